@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"backend/internal/models"
+	"backend/internal/utils"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	redactedValue   = "[redacted]"
+)
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+var sensitiveFields = map[string]struct{}{
+	"password":      {},
+	"password_hash": {},
+	"token":         {},
+	"token_hash":    {},
+	"code_hash":     {},
+	"secret":        {},
+	"totp_secret":   {},
+}
+
+func Record(db *gorm.DB, entry models.AuditLog, details map[string]interface{}) {
+	if details != nil {
+		encoded, err := json.Marshal(details)
+		if err != nil {
+			log.Printf("Failed to encode audit details for %s: %v", entry.Action, err)
+		} else {
+			entry.Details = string(encoded)
+		}
+	}
+
+	if entry.Details == "" {
+		entry.Details = "{}"
+	}
+
+	if entry.Changes == "" {
+		entry.Changes = "{}"
+	}
+
+	if err := db.Create(&entry).Error; err != nil {
+		log.Printf("Failed to record audit entry %s: %v", entry.Action, err)
+	}
+}
+
+func FromContext(c *gin.Context, action, entityType string, entityID uint) models.AuditLog {
+	entry := models.AuditLog{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		IP:         c.ClientIP(),
+		RequestID:  c.GetString("requestID"),
+	}
+
+	if userID, ok := c.Value("userID").(uint); ok {
+		entry.ActorID = &userID
+	}
+	if impersonatorID, ok := c.Value("impersonatorID").(uint); ok {
+		entry.ImpersonatorID = &impersonatorID
+	}
+	if organizationID, ok := c.Value("organizationID").(uint); ok {
+		entry.OrganizationID = &organizationID
+	}
+
+	return entry
+}
+
+func RecordChange(c *gin.Context, db *gorm.DB, action, entityType string, entityID uint, before, after interface{}, details map[string]interface{}) {
+	RecordDiff(db, FromContext(c, action, entityType, entityID), before, after, details)
+}
+
+func RecordDiff(db *gorm.DB, entry models.AuditLog, before, after interface{}, details map[string]interface{}) {
+	encoded, err := json.Marshal(Diff(before, after))
+	if err != nil {
+		log.Printf("Failed to encode audit changes for %s: %v", entry.Action, err)
+	} else {
+		entry.Changes = string(encoded)
+	}
+
+	Record(db, entry, details)
+}
+
+func Diff(before, after interface{}) map[string]map[string]interface{} {
+	beforeFields := toFields(before)
+	afterFields := toFields(after)
+
+	changes := map[string]map[string]interface{}{}
+
+	for key, value := range beforeFields {
+		if other, ok := afterFields[key]; !ok || !reflect.DeepEqual(value, other) {
+			changes[key] = map[string]interface{}{"before": redact(key, value), "after": redact(key, other)}
+		}
+	}
+
+	for key, value := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			changes[key] = map[string]interface{}{"before": nil, "after": redact(key, value)}
+		}
+	}
+
+	return changes
+}
+
+func toFields(value interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return fields
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return map[string]interface{}{}
+	}
+
+	return fields
+}
+
+func redact(key string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if _, ok := sensitiveFields[strings.ToLower(key)]; ok {
+		return redactedValue
+	}
+	return value
+}
+
+func RequestID(c *gin.Context) {
+	requestID := c.GetHeader(RequestIDHeader)
+
+	if !requestIDPattern.MatchString(requestID) {
+		generated, err := utils.GenerateRandomToken(12)
+		if err != nil {
+			log.Printf("Failed to generate request id: %v", err)
+		}
+		requestID = generated
+	}
+
+	c.Set("requestID", requestID)
+	c.Header(RequestIDHeader, requestID)
+
+	c.Next()
+}
+
+func ImpersonationTrail(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if _, ok := c.Value("impersonatorID").(uint); !ok {
+			return
+		}
+
+		userID, _ := c.Value("userID").(uint)
+
+		Record(db, FromContext(c, "impersonation.request", "user", userID), map[string]interface{}{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"status": c.Writer.Status(),
+		})
+	}
+}
@@ -1,17 +1,28 @@
 package auth
 
 import (
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"net/http"
+	"strings"
 	"time"
 )
 
-var jwtKey = []byte("SecretKey")
+const accessTokenType = "access"
 
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID         uint              `json:"user_id"`
+	Role           config.RoleChoice `json:"role"`
+	EmailVerified  bool              `json:"email_verified"`
+	FamilyID       string            `json:"fid,omitempty"`
+	OrganizationID uint              `json:"org,omitempty"`
+	TokenType      string            `json:"typ"`
+	ImpersonatorID *uint             `json:"imp,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -29,18 +40,46 @@ func ParseToken(authToken string, parsedToken *string) error {
 	return nil
 }
 
-func GenerateToken(token *string, userID uint) error {
-	expirationTime := time.Now().Add(24 * time.Hour)
+func GenerateToken(token *string, user *models.User, familyID string, organizationID uint) error {
+	claims := &Claims{
+		UserID:         user.ID,
+		Role:           user.Role,
+		EmailVerified:  user.EmailVerifiedAt != nil,
+		FamilyID:       familyID,
+		OrganizationID: organizationID,
+	}
 
+	return generateAccessToken(token, claims, config.AccessTokenLifetime)
+}
+
+func GenerateImpersonationToken(token *string, user *models.User, impersonatorID, organizationID uint) error {
 	claims := &Claims{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-		},
+		UserID:         user.ID,
+		Role:           user.Role,
+		EmailVerified:  user.EmailVerifiedAt != nil,
+		OrganizationID: organizationID,
+		ImpersonatorID: &impersonatorID,
 	}
 
-	generatedToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := generatedToken.SignedString(jwtKey)
+	return generateAccessToken(token, claims, config.ImpersonationTokenLifetime)
+}
+
+func generateAccessToken(token *string, claims *Claims, lifetime time.Duration) error {
+	jti, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return err
+	}
+
+	issuedAt := time.Now()
+
+	claims.TokenType = accessTokenType
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        jti,
+		IssuedAt:  jwt.NewNumericDate(issuedAt),
+		ExpiresAt: jwt.NewNumericDate(issuedAt.Add(lifetime)),
+	}
+
+	tokenString, err := signToken(claims)
 
 	if err != nil {
 		return err
@@ -64,19 +103,55 @@ func Authenticate(c *gin.Context) {
 		return
 	}
 
-	token, err := jwt.ParseWithClaims(parsedToken, claims, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected sign method")
-		}
-		return jwtKey, nil
-	})
+	if strings.HasPrefix(parsedToken, PersonalTokenPrefix) {
+		authenticatePersonalToken(c, parsedToken)
+		return
+	}
+
+	token, err := jwt.ParseWithClaims(parsedToken, claims, verificationKey)
 
-	if err != nil || !token.Valid {
+	if err != nil || !token.Valid || claims.TokenType != accessTokenType {
 		c.JSON(http.StatusUnauthorized, "unauthorized")
+		c.Abort()
+		return
+	}
+
+	if claims.ID == "" || IsTokenRevoked(database.DB, claims.ID) {
+		c.JSON(http.StatusUnauthorized, "token has been revoked")
+		c.Abort()
+		return
+	}
+
+	if claims.FamilyID != "" && !IsSessionActive(database.DB, claims.FamilyID, c.ClientIP()) {
+		c.JSON(http.StatusUnauthorized, "session has been terminated")
+		c.Abort()
+		return
+	}
+
+	if !IsUserActive(database.DB, claims.UserID) {
+		c.JSON(http.StatusUnauthorized, "account is deactivated")
+		c.Abort()
+		return
+	}
+
+	if claims.ImpersonatorID != nil && !IsActiveAdmin(database.DB, *claims.ImpersonatorID) {
+		c.JSON(http.StatusUnauthorized, "impersonation is no longer allowed")
+		c.Abort()
 		return
 	}
 
 	c.Set("userID", claims.UserID)
+	c.Set("role", claims.Role)
+	c.Set("emailVerified", claims.EmailVerified)
+	c.Set("claims", claims)
+
+	if claims.OrganizationID != 0 {
+		c.Set("organizationID", claims.OrganizationID)
+	}
+
+	if claims.ImpersonatorID != nil {
+		c.Set("impersonatorID", *claims.ImpersonatorID)
+	}
 
 	c.Next()
 }
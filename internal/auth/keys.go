@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+)
+
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey interface{}
+	PublicKey  interface{}
+}
+
+type signingKeyConfig struct {
+	ID             string `json:"kid"`
+	Algorithm      string `json:"alg"`
+	Secret         string `json:"secret"`
+	PrivateKeyFile string `json:"private_key_file"`
+	PublicKeyFile  string `json:"public_key_file"`
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+var (
+	signingKeys = map[string]*SigningKey{}
+	activeKeyID string
+)
+
+func InitSigningKeys() {
+	if err := LoadSigningKeys(); err != nil {
+		log.Fatal("Failed to load JWT signing keys: ", err)
+	}
+}
+
+func LoadSigningKeys() error {
+	var configs []signingKeyConfig
+
+	if path := os.Getenv("JWT_KEYS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &configs); err != nil {
+			return fmt.Errorf("invalid %s: %w", path, err)
+		}
+	} else if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		configs = append(configs, signingKeyConfig{ID: "default", Algorithm: "HS256", Secret: secret})
+	} else {
+		return errors.New("either JWT_KEYS_FILE or JWT_SECRET must be set")
+	}
+
+	keys := map[string]*SigningKey{}
+
+	for _, cfg := range configs {
+		if cfg.ID == "" {
+			return errors.New("every signing key must have a kid")
+		}
+		if _, exists := keys[cfg.ID]; exists {
+			return fmt.Errorf("duplicate signing key %q", cfg.ID)
+		}
+
+		key, err := loadSigningKey(cfg)
+		if err != nil {
+			return fmt.Errorf("signing key %q: %w", cfg.ID, err)
+		}
+
+		keys[cfg.ID] = key
+	}
+
+	activeID := os.Getenv("JWT_ACTIVE_KID")
+	if activeID == "" && len(configs) > 0 {
+		activeID = configs[0].ID
+	}
+
+	active, ok := keys[activeID]
+	if !ok {
+		return fmt.Errorf("active signing key %q is not configured", activeID)
+	}
+	if active.PrivateKey == nil {
+		return fmt.Errorf("active signing key %q has no private key", activeID)
+	}
+
+	signingKeys = keys
+	activeKeyID = activeID
+
+	return nil
+}
+
+func loadSigningKey(cfg signingKeyConfig) (*SigningKey, error) {
+	key := &SigningKey{ID: cfg.ID}
+
+	switch cfg.Algorithm {
+	case "HS256", "HS384", "HS512":
+		if len(cfg.Secret) < 32 {
+			return nil, errors.New("HMAC secret must be at least 32 characters")
+		}
+		key.Method = jwt.GetSigningMethod(cfg.Algorithm)
+		key.PrivateKey = []byte(cfg.Secret)
+		key.PublicKey = []byte(cfg.Secret)
+	case "RS256", "RS384", "RS512":
+		key.Method = jwt.GetSigningMethod(cfg.Algorithm)
+		if cfg.PrivateKeyFile != "" {
+			data, err := os.ReadFile(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.PrivateKey = privateKey
+			key.PublicKey = &privateKey.PublicKey
+		} else if cfg.PublicKeyFile != "" {
+			data, err := os.ReadFile(cfg.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.PublicKey = publicKey
+		} else {
+			return nil, errors.New("private_key_file or public_key_file is required")
+		}
+	case "EdDSA":
+		key.Method = jwt.SigningMethodEdDSA
+		if cfg.PrivateKeyFile != "" {
+			data, err := os.ReadFile(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			privateKey, err := jwt.ParseEdPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.PrivateKey = privateKey
+			key.PublicKey = privateKey.(ed25519.PrivateKey).Public()
+		} else if cfg.PublicKeyFile != "" {
+			data, err := os.ReadFile(cfg.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			publicKey, err := jwt.ParseEdPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.PublicKey = publicKey
+		} else {
+			return nil, errors.New("private_key_file or public_key_file is required")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", cfg.Algorithm)
+	}
+
+	return key, nil
+}
+
+func signToken(claims jwt.Claims) (string, error) {
+	key, ok := signingKeys[activeKeyID]
+	if !ok {
+		return "", errors.New("no active signing key")
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.PrivateKey)
+}
+
+func verificationKey(t *jwt.Token) (interface{}, error) {
+	kid, ok := t.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("token has no kid header")
+	}
+
+	key, ok := signingKeys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected sign method")
+	}
+
+	return key.PublicKey, nil
+}
+
+func PublicJWKs() []JWK {
+	ids := make([]string, 0, len(signingKeys))
+	for id := range signingKeys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := []JWK{}
+
+	for _, id := range ids {
+		key := signingKeys[id]
+
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Method.Alg(),
+				N:         base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Method.Alg(),
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+
+	return jwks
+}
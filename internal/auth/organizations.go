@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/permissions"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func RequireOrganization(c *gin.Context) {
+	organizationID, ok := CurrentOrganizationID(c)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "no organization selected"})
+		c.Abort()
+		return
+	}
+
+	if HasRole(c, config.Admin) {
+		c.Next()
+		return
+	}
+
+	userID, _ := CurrentUserID(c)
+
+	role, ok := permissions.OrganizationRole(database.DB, organizationID, userID)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not a member of this organization"})
+		c.Abort()
+		return
+	}
+
+	c.Set("organizationRole", role)
+	c.Next()
+}
+
+func CurrentOrganizationRole(c *gin.Context) (config.OrganizationRoleChoice, bool) {
+	role, ok := c.Value("organizationRole").(config.OrganizationRoleChoice)
+	return role, ok
+}
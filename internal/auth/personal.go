@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+const (
+	PersonalTokenPrefix = "pat_"
+	personalTokenType   = "personal"
+	lastUsedResolution  = time.Minute
+)
+
+func GeneratePersonalToken(token *string, prefix *string) error {
+	rawToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	*token = PersonalTokenPrefix + rawToken
+	*prefix = (*token)[:len(PersonalTokenPrefix)+8]
+
+	return nil
+}
+
+func authenticatePersonalToken(c *gin.Context, rawToken string) {
+	var personalToken models.PersonalAccessToken
+
+	err := database.DB.
+		Where("token_hash = ? AND revoked_at IS NULL", utils.HashToken(rawToken)).
+		First(&personalToken).Error
+
+	if err != nil || (personalToken.ExpiresAt != nil && time.Now().After(*personalToken.ExpiresAt)) {
+		c.JSON(http.StatusUnauthorized, "unauthorized")
+		c.Abort()
+		return
+	}
+
+	var user models.User
+
+	if err := database.DB.First(&user, personalToken.UserID).Error; err != nil || !user.IsActive {
+		c.JSON(http.StatusUnauthorized, "unauthorized")
+		c.Abort()
+		return
+	}
+
+	now := time.Now()
+	if personalToken.LastUsedAt == nil || now.Sub(*personalToken.LastUsedAt) > lastUsedResolution {
+		database.DB.Model(&personalToken).UpdateColumn("last_used_at", now)
+	}
+
+	c.Set("userID", user.ID)
+	c.Set("role", user.Role)
+	c.Set("emailVerified", user.EmailVerifiedAt != nil)
+	c.Set("tokenType", personalTokenType)
+	c.Set("scopes", personalToken.ScopeList())
+
+	if personalToken.OrganizationID != 0 {
+		c.Set("organizationID", personalToken.OrganizationID)
+	}
+
+	c.Next()
+}
+
+func HasScope(c *gin.Context, scope config.ScopeChoice) bool {
+	scopes, ok := c.Value("scopes").([]config.ScopeChoice)
+	if !ok {
+		return true
+	}
+
+	for _, granted := range scopes {
+		if granted == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func RequireScope(scope config.ScopeChoice) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if !HasScope(c, scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "token is missing the " + string(scope) + " scope"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func DenyPersonalTokens(c *gin.Context) {
+	if tokenType, _ := c.Value("tokenType").(string); tokenType == personalTokenType {
+		c.JSON(http.StatusForbidden, gin.H{"error": "personal access tokens cannot be used here"})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
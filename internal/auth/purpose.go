@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"time"
+)
+
+const (
+	EmailVerificationPurpose = "email_verify"
+	MFAChallengePurpose      = "mfa_challenge"
+	MFAEnrollmentPurpose     = "mfa_enroll"
+	EmailChangePurpose       = "email_change"
+)
+
+type PurposeClaims struct {
+	UserID  uint   `json:"user_id"`
+	Email   string `json:"email,omitempty"`
+	Purpose string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func GeneratePurposeToken(token *string, claims PurposeClaims, lifetime time.Duration) error {
+	issuedAt := time.Now()
+
+	claims.IssuedAt = jwt.NewNumericDate(issuedAt)
+	claims.ExpiresAt = jwt.NewNumericDate(issuedAt.Add(lifetime))
+
+	tokenString, err := signToken(&claims)
+	if err != nil {
+		return err
+	}
+
+	*token = tokenString
+
+	return nil
+}
+
+func ParsePurposeToken(tokenString, purpose string, claims *PurposeClaims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey)
+
+	if err != nil || !token.Valid || claims.Purpose != purpose {
+		return errors.New("invalid or expired token")
+	}
+
+	return nil
+}
+
+func AuthenticateMFAEnrollment(c *gin.Context) {
+	var parsedToken string
+
+	if err := ParseToken(c.GetHeader("Authorization"), &parsedToken); err == nil {
+		var claims PurposeClaims
+
+		if err := ParsePurposeToken(parsedToken, MFAEnrollmentPurpose, &claims); err == nil {
+			c.Set("userID", claims.UserID)
+			c.Next()
+			return
+		}
+	}
+
+	Authenticate(c)
+}
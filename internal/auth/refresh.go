@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+)
+
+func IssueRefreshToken(db *gorm.DB, token *string, userID uint, familyID string) error {
+	rawToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	refreshToken := models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(config.RefreshTokenLifetime),
+	}
+
+	if err := db.Create(&refreshToken).Error; err != nil {
+		return err
+	}
+
+	*token = rawToken
+
+	return nil
+}
+
+func RotateRefreshToken(db *gorm.DB, rawToken string, token *string, rotated *models.RefreshToken) error {
+	if err := db.Where("token_hash = ?", utils.HashToken(rawToken)).First(rotated).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	if rotated.RevokedAt != nil {
+		if err := RevokeFamily(db, rotated.FamilyID); err != nil {
+			return err
+		}
+		return ErrRefreshTokenReused
+	}
+
+	if time.Now().After(rotated.ExpiresAt) {
+		return ErrInvalidRefreshToken
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", rotated.ID).
+			Update("revoked_at", time.Now())
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		return IssueRefreshToken(tx, token, rotated.UserID, rotated.FamilyID)
+	})
+
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if err := RevokeFamily(db, rotated.FamilyID); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+func RevokeFamily(db *gorm.DB, familyID string) error {
+	if err := db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	return db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func RevokeUserRefreshTokens(db *gorm.DB, userID uint) error {
+	if err := db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	return db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func RevokeOtherFamilies(db *gorm.DB, userID uint, familyID string) error {
+	if err := db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, familyID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	return db.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func RevokeAccessToken(db *gorm.DB, claims *Claims) error {
+	revokedToken := models.RevokedToken{
+		JTI:       claims.ID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+
+	return db.Where(models.RevokedToken{JTI: claims.ID}).FirstOrCreate(&revokedToken).Error
+}
+
+func IsUserActive(db *gorm.DB, userID uint) bool {
+	var count int64
+	if err := db.Model(&models.User{}).Where("id = ? AND is_active", userID).Count(&count).Error; err != nil {
+		return false
+	}
+	return count > 0
+}
+
+func IsActiveAdmin(db *gorm.DB, userID uint) bool {
+	var count int64
+	if err := db.Model(&models.User{}).Where("id = ? AND is_active AND role = ?", userID, config.Admin).Count(&count).Error; err != nil {
+		return false
+	}
+	return count > 0
+}
+
+func IsTokenRevoked(db *gorm.DB, jti string) bool {
+	var count int64
+	if err := db.Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return true
+	}
+	return count > 0
+}
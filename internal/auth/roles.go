@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"backend/internal/config"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func CurrentUserID(c *gin.Context) (uint, bool) {
+	userID, ok := c.Value("userID").(uint)
+	return userID, ok
+}
+
+func HasRole(c *gin.Context, roles ...config.RoleChoice) bool {
+	role, ok := c.Value("role").(config.RoleChoice)
+	if !ok {
+		return false
+	}
+
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func RequireRoles(roles ...config.RoleChoice) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasRole(c, roles...) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient role"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ImpersonatorID(c *gin.Context) (uint, bool) {
+	impersonatorID, ok := c.Value("impersonatorID").(uint)
+	return impersonatorID, ok
+}
+
+func ForbidImpersonation(c *gin.Context) {
+	if _, impersonating := ImpersonatorID(c); impersonating {
+		c.JSON(http.StatusForbidden, gin.H{"error": "this action is not allowed while impersonating"})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+func CurrentOrganizationID(c *gin.Context) (uint, bool) {
+	organizationID, ok := c.Value("organizationID").(uint)
+	return organizationID, ok
+}
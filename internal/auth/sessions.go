@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"backend/internal/models"
+	"backend/internal/utils"
+	"gorm.io/gorm"
+	"time"
+)
+
+const sessionSeenResolution = time.Minute
+
+func StartSession(db *gorm.DB, userID, organizationID uint, userAgent, ip string) (string, error) {
+	sessionID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	session := models.Session{
+		ID:             sessionID,
+		UserID:         userID,
+		OrganizationID: organizationID,
+		UserAgent:      userAgent,
+		IP:             ip,
+		CreatedAt:      now,
+		LastSeenAt:     now,
+	}
+
+	if err := db.Create(&session).Error; err != nil {
+		return "", err
+	}
+
+	return sessionID, nil
+}
+
+func IsSessionActive(db *gorm.DB, sessionID, ip string) bool {
+	var session models.Session
+
+	if err := db.Where("id = ? AND revoked_at IS NULL", sessionID).First(&session).Error; err != nil {
+		return false
+	}
+
+	if now := time.Now(); now.Sub(session.LastSeenAt) > sessionSeenResolution {
+		db.Model(&session).Updates(map[string]interface{}{"last_seen_at": now, "ip": ip})
+	}
+
+	return true
+}
+
+func SessionOrganizationID(db *gorm.DB, sessionID string) uint {
+	var organizationID uint
+
+	db.Model(&models.Session{}).Where("id = ?", sessionID).Pluck("organization_id", &organizationID)
+
+	return organizationID
+}
+
+func SwitchSessionOrganization(db *gorm.DB, sessionID string, organizationID uint) error {
+	return db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("organization_id", organizationID).Error
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"backend/internal/config"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func RequireVerifiedEmail(c *gin.Context) {
+	if config.UnverifiedEmailPolicy() != config.BlockUnverifiedWrites {
+		c.Next()
+		return
+	}
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		c.Next()
+		return
+	}
+
+	if verified, _ := c.Value("emailVerified").(bool); !verified {
+		c.JSON(http.StatusForbidden, gin.H{"error": "email address is not verified"})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
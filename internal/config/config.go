@@ -1,5 +1,12 @@
 package config
 
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
 type GenderChoice string
 
 const (
@@ -15,3 +22,169 @@ const (
 	Completed StatusChoice = "completed"
 	Expired   StatusChoice = "expired"
 )
+
+func (s StatusChoice) IsValid() bool {
+	switch s {
+	case Created, InProcess, Completed, Expired:
+		return true
+	}
+	return false
+}
+
+type RoleChoice string
+
+const (
+	Admin   RoleChoice = "admin"
+	Manager RoleChoice = "manager"
+	Member  RoleChoice = "member"
+	Viewer  RoleChoice = "viewer"
+)
+
+func (r RoleChoice) IsValid() bool {
+	switch r {
+	case Admin, Manager, Member, Viewer:
+		return true
+	}
+	return false
+}
+
+type ProjectRoleChoice string
+
+const (
+	ProjectOwner       ProjectRoleChoice = "owner"
+	ProjectMaintainer  ProjectRoleChoice = "maintainer"
+	ProjectContributor ProjectRoleChoice = "contributor"
+	ProjectReadOnly    ProjectRoleChoice = "read_only"
+)
+
+func (r ProjectRoleChoice) rank() int {
+	switch r {
+	case ProjectOwner:
+		return 4
+	case ProjectMaintainer:
+		return 3
+	case ProjectContributor:
+		return 2
+	case ProjectReadOnly:
+		return 1
+	}
+	return 0
+}
+
+func (r ProjectRoleChoice) IsValid() bool {
+	return r.rank() > 0
+}
+
+func (r ProjectRoleChoice) AtLeast(min ProjectRoleChoice) bool {
+	return r.IsValid() && r.rank() >= min.rank()
+}
+
+type OrganizationRoleChoice string
+
+const (
+	OrganizationOwner  OrganizationRoleChoice = "owner"
+	OrganizationAdmin  OrganizationRoleChoice = "admin"
+	OrganizationMember OrganizationRoleChoice = "member"
+)
+
+func (r OrganizationRoleChoice) rank() int {
+	switch r {
+	case OrganizationOwner:
+		return 3
+	case OrganizationAdmin:
+		return 2
+	case OrganizationMember:
+		return 1
+	}
+	return 0
+}
+
+func (r OrganizationRoleChoice) IsValid() bool {
+	return r.rank() > 0
+}
+
+func (r OrganizationRoleChoice) AtLeast(min OrganizationRoleChoice) bool {
+	return r.IsValid() && r.rank() >= min.rank()
+}
+
+const (
+	AccessTokenLifetime  = 15 * time.Minute
+	RefreshTokenLifetime = 30 * 24 * time.Hour
+
+	PasswordResetTokenLifetime = time.Hour
+
+	EmailVerificationTokenLifetime = 24 * time.Hour
+	VerificationResendInterval     = time.Minute
+
+	MFATokenLifetime   = 5 * time.Minute
+	RecoveryCodesCount = 10
+
+	OIDCLoginStateLifetime = 10 * time.Minute
+
+	ImpersonationTokenLifetime = 10 * time.Minute
+
+	ProjectInvitationLifetime       = 7 * 24 * time.Hour
+	ProjectInvitationResendInterval = time.Minute
+)
+
+type ScopeChoice string
+
+const (
+	ReadOnlyScope      ScopeChoice = "read-only"
+	ProjectsWriteScope ScopeChoice = "projects:write"
+	TasksWriteScope    ScopeChoice = "tasks:write"
+)
+
+func (s ScopeChoice) IsValid() bool {
+	switch s {
+	case ReadOnlyScope, ProjectsWriteScope, TasksWriteScope:
+		return true
+	}
+	return false
+}
+
+type VerificationPolicyChoice string
+
+const (
+	AllowUnverified       VerificationPolicyChoice = "allow"
+	BlockUnverifiedLogin  VerificationPolicyChoice = "block_login"
+	BlockUnverifiedWrites VerificationPolicyChoice = "block_writes"
+)
+
+func UnverifiedEmailPolicy() VerificationPolicyChoice {
+	switch policy := VerificationPolicyChoice(os.Getenv("UNVERIFIED_EMAIL_POLICY")); policy {
+	case BlockUnverifiedLogin, BlockUnverifiedWrites:
+		return policy
+	}
+	return AllowUnverified
+}
+
+func MFARequiredFor(role RoleChoice) bool {
+	for _, required := range strings.Split(os.Getenv("MFA_REQUIRED_ROLES"), ",") {
+		if RoleChoice(strings.TrimSpace(required)) == role {
+			return true
+		}
+	}
+	return false
+}
+
+func GetEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func GetEnvBool(key string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
@@ -1,14 +1,19 @@
 package database
 
 import (
+	"backend/internal/audit"
+	"backend/internal/config"
 	"backend/internal/models"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var DB *gorm.DB
@@ -42,7 +47,161 @@ func InitDB() {
 		log.Fatal("Failed connection to database", err)
 	}
 
-	if err := DB.AutoMigrate(&models.User{}, &models.Project{}, &models.Task{}); err != nil {
+	if err := DB.SetupJoinTable(&models.Project{}, "Executors", &models.ProjectUser{}); err != nil {
+		log.Fatal("Failed to set up project members table: ", err)
+	}
+
+	if err := DB.SetupJoinTable(&models.Project{}, "Teams", &models.ProjectTeam{}); err != nil {
+		log.Fatal("Failed to set up project teams table: ", err)
+	}
+
+	needsOrganizationBackfill := !DB.Migrator().HasTable(&models.Organization{})
+
+	if err := DB.AutoMigrate(
+		&models.User{},
+		&models.Project{},
+		&models.Task{},
+		&models.ProjectUser{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.PasswordResetToken{},
+		&models.PersonalAccessToken{},
+		&models.RecoveryCode{},
+		&models.LoginFailure{},
+		&models.AuditLog{},
+		&models.OIDCLoginState{},
+		&models.Session{},
+		&models.PasswordHistory{},
+		&models.Organization{},
+		&models.OrganizationMember{},
+		&models.Team{},
+		&models.ProjectTeam{},
+		&models.ProjectInvitation{},
+		&models.StatusTransition{},
+		&models.ProjectTransition{},
+	); err != nil {
 		log.Fatal("Failed to automigrate models: ", err)
 	}
+
+	for _, index := range []string{"idx_projects_organization_title", "idx_tasks_organization_title"} {
+		if err := DB.Exec("DROP INDEX IF EXISTS " + index).Error; err != nil {
+			log.Fatal("Failed to drop legacy title index: ", err)
+		}
+	}
+
+	if err := DB.Model(&models.User{}).Where("role = '' OR role IS NULL").Update("role", config.Member).Error; err != nil {
+		log.Fatal("Failed to backfill user roles: ", err)
+	}
+
+	if needsOrganizationBackfill {
+		if err := backfillDefaultOrganization(DB); err != nil {
+			log.Fatal("Failed to backfill default organization: ", err)
+		}
+	}
+
+	if err := protectAuditLogs(DB); err != nil {
+		log.Fatal("Failed to make audit log append-only: ", err)
+	}
+
+	if err := bootstrapAdmin(DB, os.Getenv("ADMIN_EMAIL")); err != nil {
+		log.Fatal("Failed to bootstrap admin: ", err)
+	}
+}
+
+func bootstrapAdmin(db *gorm.DB, email string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil
+	}
+
+	var user models.User
+	var before models.UserSchema
+	promoted := false
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var admins []models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("role = ? AND is_active", config.Admin).Find(&admins).Error; err != nil {
+			return err
+		}
+
+		if len(admins) > 0 {
+			return nil
+		}
+
+		if err := tx.Where("LOWER(email) = ? AND is_active", email).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("ADMIN_EMAIL %s doesn't match an active user, no admin was bootstrapped", email)
+				return nil
+			}
+			return err
+		}
+
+		before = user.ToSchema()
+		promoted = true
+		return tx.Model(&user).Update("role", config.Admin).Error
+	})
+
+	if err != nil || !promoted {
+		return err
+	}
+
+	audit.RecordDiff(db, models.AuditLog{Action: "user.role.bootstrap", EntityType: "user", EntityID: user.ID}, before, user.ToSchema(), nil)
+	log.Printf("Promoted %s to admin from ADMIN_EMAIL", email)
+
+	return nil
+}
+
+func protectAuditLogs(db *gorm.DB) error {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql`,
+		"DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs",
+		"CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()",
+		"DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs",
+		"CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only()",
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func backfillDefaultOrganization(db *gorm.DB) error {
+	var users int64
+	if err := db.Model(&models.User{}).Count(&users).Error; err != nil || users == 0 {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		organization := models.Organization{Name: "Default", Slug: "default"}
+		if err := tx.Create(&organization).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(
+			"INSERT INTO organization_members (organization_id, user_id, role, created_at) SELECT ?, id, CASE WHEN role = ? THEN ? ELSE ? END, NOW() FROM users WHERE deleted_at IS NULL",
+			organization.ID, config.Admin, config.OrganizationOwner, config.OrganizationMember,
+		).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&models.Project{}, &models.Task{}, &models.Session{}, &models.PersonalAccessToken{}} {
+			if err := tx.Unscoped().Model(model).
+				Where("organization_id IS NULL OR organization_id = 0").
+				Update("organization_id", organization.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
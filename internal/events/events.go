@@ -0,0 +1,90 @@
+package events
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+type Event struct {
+	Type           string                 `json:"type"`
+	EntityType     string                 `json:"entity_type"`
+	EntityID       uint                   `json:"entity_id"`
+	OrganizationID uint                   `json:"organization_id"`
+	Data           map[string]interface{} `json:"data"`
+	OccurredAt     time.Time              `json:"occurred_at"`
+}
+
+type Publisher interface {
+	Publish(event Event) error
+}
+
+var Default Publisher = NewMemoryPublisher()
+
+func InitPublisher() {
+	switch os.Getenv("EVENTS_PUBLISHER") {
+	case "", "log":
+		Default = &LogPublisher{}
+	case "memory":
+		Default = NewMemoryPublisher()
+	default:
+		log.Fatalf("Unknown EVENTS_PUBLISHER %q", os.Getenv("EVENTS_PUBLISHER"))
+	}
+}
+
+func Publish(event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	if err := Default.Publish(event); err != nil {
+		log.Printf("Failed to publish event %s for %s %d: %v", event.Type, event.EntityType, event.EntityID, err)
+	}
+}
+
+type LogPublisher struct{}
+
+func (p *LogPublisher) Publish(event Event) error {
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("event %s", encoded)
+
+	return nil
+}
+
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, event)
+
+	return nil
+}
+
+func (p *MemoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Event(nil), p.events...)
+}
+
+func (p *MemoryPublisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = nil
+}
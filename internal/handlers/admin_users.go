@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"backend/internal/validators"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultUsersPageSize = 20
+	maxUsersPageSize     = 100
+)
+
+func findUserByID(c *gin.Context, user *models.User) bool {
+	if err := database.DB.First(user, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving user"})
+		}
+		return false
+	}
+	return true
+}
+
+var errLastActiveAdmin = errors.New("user is the last active admin")
+
+func checkNotLastActiveAdmin(tx *gorm.DB, userID uint) error {
+	var adminIDs []uint
+
+	if err := tx.Model(&models.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND is_active", config.Admin).Order("id").Pluck("id", &adminIDs).Error; err != nil {
+		return err
+	}
+
+	if len(adminIDs) == 1 && adminIDs[0] == userID {
+		return errLastActiveAdmin
+	}
+
+	return nil
+}
+
+func ReadUsers(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect page"})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultUsersPageSize)))
+	if err != nil || pageSize < 1 || pageSize > maxUsersPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect page_size"})
+		return
+	}
+
+	query := database.DB.Model(&models.User{})
+
+	if name := c.Query("name"); name != "" {
+		pattern := "%" + strings.ToLower(name) + "%"
+		query = query.Where("LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ?", pattern, pattern)
+	}
+
+	if email := c.Query("email"); email != "" {
+		query = query.Where("LOWER(email) LIKE ?", "%"+strings.ToLower(email)+"%")
+	}
+
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	if isActive := c.Query("is_active"); isActive != "" {
+		active, err := strconv.ParseBool(isActive)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect is_active"})
+			return
+		}
+		query = query.Where("is_active = ?", active)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't count users"})
+		return
+	}
+
+	var users []models.User
+	if err := query.Order("id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find users"})
+		return
+	}
+
+	serializedUsers := []models.UserSchema{}
+	for _, user := range users {
+		serializedUsers = append(serializedUsers, user.ToSchema())
+	}
+
+	c.JSON(http.StatusOK, models.UserListSchema{Results: serializedUsers, Total: total, Page: page, PageSize: pageSize})
+}
+
+func ReadUser(c *gin.Context) {
+	var user models.User
+	if !findUserByID(c, &user) {
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToSchema())
+}
+
+func UpdateUser(c *gin.Context) {
+	var input models.AdminUserUpdateInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var user models.User
+	if !findUserByID(c, &user) {
+		return
+	}
+
+	before := user.ToSchema()
+	validationErrors := make(map[string]string)
+
+	if input.FirstName != nil {
+		if *input.FirstName == "" {
+			validationErrors["first_name"] = "Поле 'first_name' обязательно"
+		}
+		user.FirstName = *input.FirstName
+	}
+
+	if input.LastName != nil {
+		if *input.LastName == "" {
+			validationErrors["last_name"] = "Поле 'last_name' обязательно"
+		}
+		user.LastName = *input.LastName
+	}
+
+	if input.BirthDate != nil {
+		if err := utils.ParseDateToTime(*input.BirthDate, &user.BirthDate); err != nil {
+			validationErrors["birth_date"] = "incorrect type of birth date"
+		}
+	}
+
+	if input.Gender != nil {
+		if *input.Gender != config.Male && *input.Gender != config.Female {
+			validationErrors["gender"] = "incorrect gender"
+		}
+		user.Gender = *input.Gender
+	}
+
+	if input.Email != nil {
+		if message := validators.ValidateEmail(*input.Email); message != "" {
+			validationErrors["email"] = message
+		}
+		user.Email = *input.Email
+	}
+
+	if len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, validators.ErrorResponse{Details: validationErrors})
+		return
+	}
+
+	err := database.DB.Model(&user).Select("first_name", "last_name", "birth_date", "gender", "email").Updates(&user).Error
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "couldn't update user"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.update", "user", user.ID, before, user.ToSchema(), nil)
+
+	c.JSON(http.StatusOK, user.ToSchema())
+}
+
+func DeactivateUser(c *gin.Context) {
+	var user models.User
+	if !findUserByID(c, &user) {
+		return
+	}
+
+	if actorID, _ := auth.CurrentUserID(c); actorID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "you cannot deactivate yourself"})
+		return
+	}
+
+	before := user.ToSchema()
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkNotLastActiveAdmin(tx, user.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		return auth.RevokeUserRefreshTokens(tx, user.ID)
+	})
+
+	if errors.Is(err, errLastActiveAdmin) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot deactivate the last admin"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't deactivate user"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.deactivate", "user", user.ID, before, user.ToSchema(), nil)
+
+	c.JSON(http.StatusOK, user.ToSchema())
+}
+
+func ReactivateUser(c *gin.Context) {
+	var user models.User
+	if !findUserByID(c, &user) {
+		return
+	}
+
+	before := user.ToSchema()
+
+	if err := database.DB.Model(&user).Update("is_active", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't reactivate user"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.reactivate", "user", user.ID, before, user.ToSchema(), nil)
+
+	c.JSON(http.StatusOK, user.ToSchema())
+}
+
+func ForcePasswordReset(c *gin.Context) {
+	var user models.User
+	if !findUserByID(c, &user) {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password_reset_required", true).Error; err != nil {
+			return err
+		}
+		return auth.RevokeUserRefreshTokens(tx, user.ID)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't require password reset"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.password.require_reset", "user", user.ID,
+		gin.H{"password_reset_required": false}, gin.H{"password_reset_required": true}, nil)
+
+	if err := sendPasswordResetEmail(database.DB, &user); err != nil {
+		log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't send password reset email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset has been required and the link has been sent"})
+}
+
+func DeleteUser(c *gin.Context) {
+	var user models.User
+	if !findUserByID(c, &user) {
+		return
+	}
+
+	if actorID, _ := auth.CurrentUserID(c); actorID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "use profile deletion to delete your own account"})
+		return
+	}
+
+	err := deleteUserAccount(database.DB, user.ID)
+
+	if errors.Is(err, errLastActiveAdmin) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot delete the last admin"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't delete user"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.delete", "user", user.ID, user.ToSchema(), nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
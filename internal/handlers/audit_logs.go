@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAuditLogsPageSize = 50
+	maxAuditLogsPageSize     = 500
+	auditLogsExportBatchSize = 1000
+)
+
+func filterAuditLogs(c *gin.Context) (*gorm.DB, bool) {
+	query := database.DB.Model(&models.AuditLog{})
+
+	for _, field := range []string{"actor_id", "impersonator_id", "organization_id", "entity_id"} {
+		value := c.Query(field)
+		if value == "" {
+			continue
+		}
+
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect " + field})
+			return nil, false
+		}
+		query = query.Where(field+" = ?", id)
+	}
+
+	for _, field := range []string{"action", "entity_type", "request_id", "ip"} {
+		if value := c.Query(field); value != "" {
+			query = query.Where(field+" = ?", value)
+		}
+	}
+
+	if from := c.Query("from"); from != "" {
+		parsedFrom, err := utils.ParseTimeFilter(from, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect from"})
+			return nil, false
+		}
+		query = query.Where("created_at >= ?", parsedFrom)
+	}
+
+	if to := c.Query("to"); to != "" {
+		parsedTo, err := utils.ParseTimeFilter(to, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect to"})
+			return nil, false
+		}
+		query = query.Where("created_at < ?", parsedTo)
+	}
+
+	return query, true
+}
+
+func ReadAuditLogs(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect page"})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultAuditLogsPageSize)))
+	if err != nil || pageSize < 1 || pageSize > maxAuditLogsPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect page_size"})
+		return
+	}
+
+	query, ok := filterAuditLogs(c)
+	if !ok {
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't count audit logs"})
+		return
+	}
+
+	var entries []models.AuditLog
+	if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find audit logs"})
+		return
+	}
+
+	serializedEntries := []models.AuditLogSchema{}
+	for _, entry := range entries {
+		serializedEntries = append(serializedEntries, entry.ToSchema())
+	}
+
+	c.JSON(http.StatusOK, models.AuditLogListSchema{Results: serializedEntries, Total: total, Page: page, PageSize: pageSize})
+}
+
+func ExportAuditLogs(c *gin.Context) {
+	query, ok := filterAuditLogs(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("audit-logs-%s.jsonl", time.Now().UTC().Format("20060102T150405Z"))
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+
+	var entries []models.AuditLog
+
+	err := query.FindInBatches(&entries, auditLogsExportBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, entry := range entries {
+			if err := encoder.Encode(entry.ToSchema()); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	}).Error
+
+	if err != nil {
+		log.Printf("Failed to export audit logs: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/permissions"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func ImpersonateUser(c *gin.Context) {
+	var input models.ImpersonationInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	var user models.User
+	if !findUserByID(c, &user) {
+		return
+	}
+
+	adminID, _ := auth.CurrentUserID(c)
+
+	if user.ID == adminID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "you cannot impersonate yourself"})
+		return
+	}
+
+	if user.Role == config.Admin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admins cannot be impersonated"})
+		return
+	}
+
+	if !user.IsActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot impersonate a deactivated user"})
+		return
+	}
+
+	var token string
+
+	if err := auth.GenerateImpersonationToken(&token, &user, adminID, permissions.DefaultOrganizationID(database.DB, user.ID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate token"})
+		return
+	}
+
+	audit.Record(database.DB, audit.FromContext(c, "impersonation.start", "user", user.ID), map[string]interface{}{"reason": input.Reason})
+
+	c.JSON(http.StatusOK, gin.H{
+		"access":     token,
+		"expires_in": int(config.ImpersonationTokenLifetime.Seconds()),
+		"user":       user.ToSchema(),
+	})
+}
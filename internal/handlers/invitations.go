@@ -0,0 +1,433 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/mailer"
+	"backend/internal/models"
+	"backend/internal/permissions"
+	"backend/internal/utils"
+	"backend/internal/validators"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+var errInvitationNotPending = errors.New("invitation is no longer pending")
+
+func sendProjectInvitationEmail(db *gorm.DB, invitation *models.ProjectInvitation, project *models.Project, inviter *models.User) error {
+	rawToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	invitation.TokenHash = utils.HashToken(rawToken)
+	invitation.SentAt = now
+	invitation.ExpiresAt = now.Add(config.ProjectInvitationLifetime)
+
+	if err := db.Save(invitation).Error; err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/invitations/accept?token=%s", os.Getenv("APP_URL"), rawToken)
+
+	return mailer.Send(mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("Invitation to the project \"%s\"", project.Title),
+		Body: fmt.Sprintf(
+			"Hello!\n\n%s %s has invited you to join the project \"%s\" as %s. Open the link below to accept or decline the invitation. It expires in %s.\n\n%s\n\nIf you don't have an account yet, register with this email address first.\n",
+			inviter.FirstName, inviter.LastName, project.Title, invitation.Role, config.ProjectInvitationLifetime, link,
+		),
+	})
+}
+
+func findInvitationByToken(c *gin.Context, token string, invitation *models.ProjectInvitation) bool {
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return false
+	}
+
+	if err := database.DB.Where("token_hash = ?", utils.HashToken(token)).First(invitation).Error; err != nil || !invitation.IsPending() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired invitation"})
+		return false
+	}
+
+	return true
+}
+
+func (u *UserHandler) findProjectInvitation(c *gin.Context, project *models.Project, invitation *models.ProjectInvitation) bool {
+	if err := u.DB.Where("project_id = ?", project.ID).First(invitation, c.Param("invitation_id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving invitation"})
+		}
+		return false
+	}
+	return true
+}
+
+func (u *UserHandler) ReadProjectInvitations(c *gin.Context) {
+	project, ok := u.findAccessibleProject(c, config.ProjectMaintainer)
+	if !ok {
+		return
+	}
+
+	var invitations []models.ProjectInvitation
+	if err := u.DB.Where("project_id = ?", project.ID).Order("created_at DESC").Find(&invitations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find invitations"})
+		return
+	}
+
+	serializedInvitations := []models.ProjectInvitationSchema{}
+	for _, invitation := range invitations {
+		serializedInvitations = append(serializedInvitations, invitation.ToSchema())
+	}
+
+	c.JSON(http.StatusOK, serializedInvitations)
+}
+
+func (u *UserHandler) CreateProjectInvitation(c *gin.Context) {
+	project, ok := u.findAccessibleProject(c, config.ProjectMaintainer)
+	if !ok {
+		return
+	}
+
+	var input models.ProjectInvitationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
+	if message := validators.ValidateEmail(input.Email); message != "" {
+		c.JSON(http.StatusBadRequest, validators.ErrorResponse{Details: map[string]string{"email": message}})
+		return
+	}
+
+	if !input.Role.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown project role"})
+		return
+	}
+
+	if !u.canGrantProjectRole(c, project.ID, input.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient project role"})
+		return
+	}
+
+	var existingUser models.User
+	if err := u.DB.Where("LOWER(email) = ?", input.Email).First(&existingUser).Error; err == nil {
+		if _, isMember := permissions.DirectProjectRole(u.DB, project.ID, existingUser.ID); isMember {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user is already a member of the project"})
+			return
+		}
+	}
+
+	var pending int64
+	u.DB.Model(&models.ProjectInvitation{}).
+		Where("project_id = ? AND email = ? AND accepted_at IS NULL AND declined_at IS NULL AND revoked_at IS NULL AND expires_at > ?", project.ID, input.Email, time.Now()).
+		Count(&pending)
+	if pending > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "this email has already been invited, resend the invitation instead"})
+		return
+	}
+
+	var inviter models.User
+	if !currentUser(c, &inviter) {
+		return
+	}
+
+	invitation := models.ProjectInvitation{
+		ProjectID:   project.ID,
+		Email:       input.Email,
+		Role:        input.Role,
+		InvitedByID: inviter.ID,
+	}
+
+	err := sendProjectInvitationEmail(u.DB, &invitation, project, &inviter)
+
+	if invitation.ID != 0 {
+		audit.RecordChange(c, u.DB, "invitation.create", "project_invitation", invitation.ID, nil, invitation.ToSchema(), nil)
+	}
+
+	if err != nil {
+		log.Printf("Failed to send invitation %d for project %d: %v", invitation.ID, project.ID, err)
+		if invitation.ID == 0 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't create invitation"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invitation was created but the email couldn't be sent, try resending it"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation.ToSchema())
+}
+
+func (u *UserHandler) ResendProjectInvitation(c *gin.Context) {
+	project, ok := u.findAccessibleProject(c, config.ProjectMaintainer)
+	if !ok {
+		return
+	}
+
+	var invitation models.ProjectInvitation
+	if !u.findProjectInvitation(c, project, &invitation) {
+		return
+	}
+
+	if !u.canGrantProjectRole(c, project.ID, invitation.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient project role"})
+		return
+	}
+
+	if status := invitation.Status(); status != "pending" && status != "expired" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invitation has already been " + status})
+		return
+	}
+
+	if time.Since(invitation.SentAt) < config.ProjectInvitationResendInterval {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "invitation has been sent recently, try again later"})
+		return
+	}
+
+	var inviter models.User
+	if !currentUser(c, &inviter) {
+		return
+	}
+
+	before := invitation.ToSchema()
+
+	if err := sendProjectInvitationEmail(u.DB, &invitation, project, &inviter); err != nil {
+		log.Printf("Failed to resend invitation %d for project %d: %v", invitation.ID, project.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't resend invitation"})
+		return
+	}
+
+	audit.RecordChange(c, u.DB, "invitation.resend", "project_invitation", invitation.ID, before, invitation.ToSchema(), nil)
+
+	c.JSON(http.StatusOK, invitation.ToSchema())
+}
+
+func (u *UserHandler) RevokeProjectInvitation(c *gin.Context) {
+	project, ok := u.findAccessibleProject(c, config.ProjectMaintainer)
+	if !ok {
+		return
+	}
+
+	var invitation models.ProjectInvitation
+	if !u.findProjectInvitation(c, project, &invitation) {
+		return
+	}
+
+	if !u.canGrantProjectRole(c, project.ID, invitation.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient project role"})
+		return
+	}
+
+	if !invitation.IsPending() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invitation is no longer pending"})
+		return
+	}
+
+	before := invitation.ToSchema()
+
+	if err := u.DB.Model(&invitation).Update("revoked_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't revoke invitation"})
+		return
+	}
+
+	after := before
+	after.Status = "revoked"
+
+	audit.RecordChange(c, u.DB, "invitation.revoke", "project_invitation", invitation.ID, before, after, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+func ReadProjectInvitations(c *gin.Context) {
+	userHandler := UserHandler{DB: database.DB}
+	userHandler.ReadProjectInvitations(c)
+}
+
+func CreateProjectInvitation(c *gin.Context) {
+	userHandler := UserHandler{DB: database.DB}
+	userHandler.CreateProjectInvitation(c)
+}
+
+func ResendProjectInvitation(c *gin.Context) {
+	userHandler := UserHandler{DB: database.DB}
+	userHandler.ResendProjectInvitation(c)
+}
+
+func RevokeProjectInvitation(c *gin.Context) {
+	userHandler := UserHandler{DB: database.DB}
+	userHandler.RevokeProjectInvitation(c)
+}
+
+func ReadMyInvitations(c *gin.Context) {
+	var user models.User
+	if !currentUser(c, &user) {
+		return
+	}
+
+	var invitations []models.ProjectInvitation
+
+	err := database.DB.
+		Where("email = ? AND accepted_at IS NULL AND declined_at IS NULL AND revoked_at IS NULL AND expires_at > ?", strings.ToLower(user.Email), time.Now()).
+		Order("created_at DESC").
+		Find(&invitations).Error
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find invitations"})
+		return
+	}
+
+	projectIDs := make([]uint, 0, len(invitations))
+	for _, invitation := range invitations {
+		projectIDs = append(projectIDs, invitation.ProjectID)
+	}
+
+	var projects []models.Project
+	if err := database.DB.Where("id IN ?", projectIDs).Find(&projects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find invitations"})
+		return
+	}
+
+	titles := make(map[uint]string, len(projects))
+	for _, project := range projects {
+		titles[project.ID] = project.Title
+	}
+
+	serializedInvitations := []models.ProjectInvitationSchema{}
+	for _, invitation := range invitations {
+		title, ok := titles[invitation.ProjectID]
+		if !ok {
+			continue
+		}
+		schema := invitation.ToSchema()
+		schema.ProjectTitle = title
+		serializedInvitations = append(serializedInvitations, schema)
+	}
+
+	c.JSON(http.StatusOK, serializedInvitations)
+}
+
+func AcceptInvitation(c *gin.Context) {
+	var input models.InvitationTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var invitation models.ProjectInvitation
+	if !findInvitationByToken(c, input.Token, &invitation) {
+		return
+	}
+
+	var user models.User
+	if !currentUser(c, &user) {
+		return
+	}
+
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "invitation was sent to a different email address"})
+		return
+	}
+
+	var project models.Project
+	if err := database.DB.First(&project, invitation.ProjectID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project no longer exists"})
+		return
+	}
+
+	before := invitation.ToSchema()
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&invitation).
+			Where("accepted_at IS NULL AND declined_at IS NULL AND revoked_at IS NULL").
+			Updates(map[string]interface{}{"accepted_at": time.Now(), "accepted_by_id": user.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInvitationNotPending
+		}
+
+		if _, ok := permissions.OrganizationRole(tx, project.OrganizationID, user.ID); !ok {
+			if err := permissions.SetOrganizationRole(tx, project.OrganizationID, user.ID, config.OrganizationMember); err != nil {
+				return err
+			}
+		}
+
+		if user.EmailVerifiedAt == nil {
+			if err := tx.Model(&user).Update("email_verified_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+
+		if current, ok := permissions.DirectProjectRole(tx, project.ID, user.ID); ok && current.AtLeast(invitation.Role) {
+			return nil
+		}
+
+		return permissions.SetProjectRole(tx, project.ID, user.ID, invitation.Role)
+	})
+
+	if err != nil {
+		if errors.Is(err, errInvitationNotPending) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired invitation"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't accept invitation"})
+		}
+		return
+	}
+
+	after := before
+	after.Status = "accepted"
+
+	audit.RecordChange(c, database.DB, "invitation.accept", "project_invitation", invitation.ID, before, after, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation accepted successfully", "project_id": project.ID, "organization_id": project.OrganizationID})
+}
+
+func DeclineInvitation(c *gin.Context) {
+	var input models.InvitationTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var invitation models.ProjectInvitation
+	if !findInvitationByToken(c, input.Token, &invitation) {
+		return
+	}
+
+	before := invitation.ToSchema()
+
+	result := database.DB.Model(&invitation).
+		Where("accepted_at IS NULL AND declined_at IS NULL AND revoked_at IS NULL").
+		Update("declined_at", time.Now())
+
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't decline invitation"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired invitation"})
+		return
+	}
+
+	after := before
+	after.Status = "declined"
+
+	audit.RecordChange(c, database.DB, "invitation.decline", "project_invitation", invitation.ID, before, after, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
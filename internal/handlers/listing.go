@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"backend/internal/pagination"
+	"backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type listFilters struct {
+	ExecutorTable  string
+	ExecutorColumn string
+	Ranges         map[string]string
+}
+
+var projectListFilters = listFilters{
+	ExecutorTable:  "project_users",
+	ExecutorColumn: "project_id",
+	Ranges: map[string]string{
+		"started":  "started_at",
+		"deadline": "deadline",
+		"created":  "created_at",
+		"updated":  "updated_at",
+	},
+}
+
+var taskListFilters = listFilters{
+	ExecutorTable:  "task_users",
+	ExecutorColumn: "task_id",
+	Ranges: map[string]string{
+		"deadline": "deadline",
+		"created":  "created_at",
+		"updated":  "updated_at",
+	},
+}
+
+var projectSortFields = map[string]pagination.Field{
+	"id":         {Column: "id", Kind: pagination.Int},
+	"title":      {Column: "title", Kind: pagination.String},
+	"status":     {Column: "status", Kind: pagination.String},
+	"started_at": {Column: "started_at", Kind: pagination.Time},
+	"deadline":   {Column: "deadline", Kind: pagination.Time},
+	"created_at": {Column: "created_at", Kind: pagination.Time},
+	"updated_at": {Column: "updated_at", Kind: pagination.Time},
+}
+
+var taskSortFields = map[string]pagination.Field{
+	"id":         {Column: "id", Kind: pagination.Int},
+	"title":      {Column: "title", Kind: pagination.String},
+	"status":     {Column: "status", Kind: pagination.String},
+	"deadline":   {Column: "deadline", Kind: pagination.Time},
+	"project_id": {Column: "project_id", Kind: pagination.Int},
+	"created_at": {Column: "created_at", Kind: pagination.Time},
+	"updated_at": {Column: "updated_at", Kind: pagination.Time},
+}
+
+func applyListFilters(c *gin.Context, query *gorm.DB, filters listFilters) (*gorm.DB, bool) {
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status IN ?", strings.Split(status, ","))
+	}
+
+	if executor := c.Query("executor"); executor != "" {
+		executorID, err := strconv.ParseUint(executor, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect executor"})
+			return nil, false
+		}
+		query = query.Where("id IN (SELECT "+filters.ExecutorColumn+" FROM "+filters.ExecutorTable+" WHERE user_id = ?)", executorID)
+	}
+
+	if title := strings.TrimSpace(c.Query("q")); title != "" {
+		query = query.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(title)+"%")
+	}
+
+	for name, column := range filters.Ranges {
+		if from := c.Query(name + "_from"); from != "" {
+			parsedFrom, err := utils.ParseTimeFilter(from, false)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect " + name + "_from"})
+				return nil, false
+			}
+			query = query.Where(column+" >= ?", parsedFrom)
+		}
+
+		if to := c.Query(name + "_to"); to != "" {
+			parsedTo, err := utils.ParseTimeFilter(to, true)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect " + name + "_to"})
+				return nil, false
+			}
+			query = query.Where(column+" < ?", parsedTo)
+		}
+	}
+
+	return query, true
+}
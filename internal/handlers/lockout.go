@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/database"
+	"backend/internal/loginguard"
+	"backend/internal/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+func throttleLogin(c *gin.Context, email string) bool {
+	wait := loginguard.RetryAfter(database.DB, email, c.ClientIP())
+	if wait <= 0 {
+		return false
+	}
+
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many failed login attempts, try again later"})
+
+	return true
+}
+
+func recordLoginFailure(c *gin.Context, email string) {
+	locked, err := loginguard.RecordFailure(database.DB, email, c.ClientIP())
+	if err != nil {
+		log.Printf("Failed to record login failure: %v", err)
+		return
+	}
+
+	for _, subject := range locked {
+		entry := audit.FromContext(c, "login.lockout", "login", 0)
+
+		if loginguard.IsAccountSubject(subject) {
+			var user models.User
+			if err := database.DB.Where("email = ?", email).First(&user).Error; err == nil {
+				entry.EntityType = "user"
+				entry.EntityID = user.ID
+			}
+		}
+
+		audit.Record(database.DB, entry, map[string]interface{}{"subject": subject})
+	}
+}
+
+func UnlockUser(c *gin.Context) {
+	var user models.User
+
+	if err := database.DB.First(&user, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving user"})
+		}
+		return
+	}
+
+	if err := loginguard.Reset(database.DB, user.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't unlock user"})
+		return
+	}
+
+	audit.Record(database.DB, audit.FromContext(c, "login.unlock", "user", user.ID), nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
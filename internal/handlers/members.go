@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/permissions"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+func (u *UserHandler) canGrantProjectRole(c *gin.Context, projectID uint, role config.ProjectRoleChoice) bool {
+	if auth.HasRole(c, config.Admin) {
+		return true
+	}
+
+	userID, _ := auth.CurrentUserID(c)
+	current, _ := permissions.ProjectRole(u.DB, projectID, userID)
+
+	if role == config.ProjectOwner || role == config.ProjectMaintainer {
+		return current == config.ProjectOwner
+	}
+
+	return current.AtLeast(config.ProjectMaintainer)
+}
+
+func (u *UserHandler) isLastOwner(projectID, userID uint) bool {
+	ownerIDs, err := permissions.ProjectOwnerIDs(u.DB, projectID)
+	if err != nil {
+		return true
+	}
+
+	return len(ownerIDs) == 1 && ownerIDs[0] == userID
+}
+
+func (u *UserHandler) ReadProjectMembers(c *gin.Context) {
+	project, ok := u.findAccessibleProject(c, config.ProjectReadOnly)
+	if !ok {
+		return
+	}
+
+	var memberships []models.ProjectUser
+	if err := u.DB.Where("project_id = ?", project.ID).Find(&memberships).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find project members"})
+		return
+	}
+
+	roles := make(map[uint]config.ProjectRoleChoice, len(memberships))
+	for _, membership := range memberships {
+		roles[membership.UserID] = membership.Role
+	}
+
+	members := []models.ProjectMemberSchema{}
+	for _, user := range project.Executors {
+		members = append(members, models.ProjectMemberSchema{User: user.ToSchema(), Role: roles[user.ID]})
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+func (u *UserHandler) SetProjectMember(c *gin.Context) {
+	project, ok := u.findAccessibleProject(c, config.ProjectMaintainer)
+	if !ok {
+		return
+	}
+
+	var input models.ProjectMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !input.Role.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown project role"})
+		return
+	}
+
+	var user models.User
+	if err := u.DB.First(&user, c.Param("user_id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving user"})
+		}
+		return
+	}
+
+	if _, ok := permissions.OrganizationRole(u.DB, project.OrganizationID, user.ID); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user is not a member of the project's organization"})
+		return
+	}
+
+	current, _ := permissions.DirectProjectRole(u.DB, project.ID, user.ID)
+
+	if !u.canGrantProjectRole(c, project.ID, input.Role) || !u.canGrantProjectRole(c, project.ID, current) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient project role"})
+		return
+	}
+
+	if current == config.ProjectOwner && input.Role != config.ProjectOwner && u.isLastOwner(project.ID, user.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project must have at least one owner"})
+		return
+	}
+
+	if err := permissions.SetProjectRole(u.DB, project.ID, user.ID, input.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update project member"})
+		return
+	}
+
+	audit.RecordChange(c, u.DB, "project.member.set", "project", project.ID,
+		gin.H{"role": current}, gin.H{"role": input.Role}, gin.H{"user_id": user.ID})
+
+	c.JSON(http.StatusOK, models.ProjectMemberSchema{User: user.ToSchema(), Role: input.Role})
+}
+
+func (u *UserHandler) RemoveProjectMember(c *gin.Context) {
+	project, ok := u.findAccessibleProject(c, config.ProjectMaintainer)
+	if !ok {
+		return
+	}
+
+	var membership models.ProjectUser
+	if err := u.DB.Where("project_id = ? AND user_id = ?", project.ID, c.Param("user_id")).First(&membership).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project member not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving project member"})
+		}
+		return
+	}
+
+	if !u.canGrantProjectRole(c, project.ID, membership.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient project role"})
+		return
+	}
+
+	if membership.Role == config.ProjectOwner && u.isLastOwner(project.ID, membership.UserID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project must have at least one owner"})
+		return
+	}
+
+	if err := u.DB.Where("project_id = ? AND user_id = ?", project.ID, membership.UserID).Delete(&models.ProjectUser{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't remove project member"})
+		return
+	}
+
+	audit.RecordChange(c, u.DB, "project.member.remove", "project", project.ID,
+		gin.H{"role": membership.Role}, nil, gin.H{"user_id": membership.UserID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project member removed successfully"})
+}
+
+func ProjectMembersViewSet(c *gin.Context) {
+	userHandler := UserHandler{DB: database.DB}
+	switch c.Request.Method {
+	case "GET":
+		userHandler.ReadProjectMembers(c)
+	case "PUT":
+		userHandler.SetProjectMember(c)
+	case "DELETE":
+		userHandler.RemoveProjectMember(c)
+	default:
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "method now allowed"})
+	}
+}
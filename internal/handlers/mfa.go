@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/loginguard"
+	"backend/internal/models"
+	"backend/internal/totp"
+	"backend/internal/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func startMFALogin(c *gin.Context, user *models.User) bool {
+	var purpose string
+
+	switch {
+	case user.TOTPEnabled:
+		purpose = auth.MFAChallengePurpose
+	case config.MFARequiredFor(user.Role):
+		purpose = auth.MFAEnrollmentPurpose
+	default:
+		return false
+	}
+
+	var token string
+
+	claims := auth.PurposeClaims{UserID: user.ID, Purpose: purpose}
+
+	if err := auth.GeneratePurposeToken(&token, claims, config.MFATokenLifetime); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate token"})
+		return true
+	}
+
+	if purpose == auth.MFAChallengePurpose {
+		c.JSON(http.StatusOK, gin.H{"mfa_required": true, "mfa_token": token})
+	} else {
+		c.JSON(http.StatusOK, gin.H{"mfa_enrollment_required": true, "mfa_token": token})
+	}
+
+	return true
+}
+
+func generateRecoveryCodes(db *gorm.DB, userID uint) ([]string, error) {
+	codes := make([]string, 0, config.RecoveryCodesCount)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		for i := 0; i < config.RecoveryCodesCount; i++ {
+			buf := make([]byte, 5)
+			if _, err := rand.Read(buf); err != nil {
+				return err
+			}
+
+			code := hex.EncodeToString(buf[:2]) + "-" + hex.EncodeToString(buf[2:])
+			codes = append(codes, code)
+
+			if err := tx.Create(&models.RecoveryCode{UserID: userID, CodeHash: utils.HashToken(code)}).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return codes, err
+}
+
+func useRecoveryCode(db *gorm.DB, userID uint, code string) bool {
+	result := db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, utils.HashToken(strings.ToLower(strings.TrimSpace(code)))).
+		Update("used_at", time.Now())
+
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+func checkTOTPCode(db *gorm.DB, user *models.User, code string) bool {
+	step, ok := totp.Validate(user.TOTPSecret, strings.TrimSpace(code), time.Now(), user.TOTPLastStep)
+	if !ok {
+		return false
+	}
+
+	result := db.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+func currentUser(c *gin.Context, user *models.User) bool {
+	userID, ok := auth.CurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, "user ID is missing in context")
+		return false
+	}
+
+	if err := database.DB.First(user, userID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, "user not found")
+		return false
+	}
+
+	return true
+}
+
+func VerifyMFALogin(c *gin.Context) {
+	var input models.MFALoginInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var claims auth.PurposeClaims
+
+	if err := auth.ParsePurposeToken(input.MFAToken, auth.MFAChallengePurpose, &claims); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+
+	if err := database.DB.First(&user, claims.UserID).Error; err != nil || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	if throttleLogin(c, user.Email) {
+		return
+	}
+
+	var verified bool
+
+	if input.RecoveryCode != "" {
+		verified = useRecoveryCode(database.DB, user.ID, input.RecoveryCode)
+	} else {
+		verified = checkTOTPCode(database.DB, &user, input.Code)
+	}
+
+	if !verified {
+		recordLoginFailure(c, user.Email)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid two-factor authentication code"})
+		return
+	}
+
+	if err := loginguard.Reset(database.DB, user.Email); err != nil {
+		log.Printf("Failed to reset login failures for user %d: %v", user.ID, err)
+	}
+
+	issueTokens(c, &user)
+}
+
+func SetupTOTP(c *gin.Context) {
+	var user models.User
+	if !currentUser(c, &user) {
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate secret"})
+		return
+	}
+
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": 0}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't save secret"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.totp.setup", "user", user.ID, nil, nil, nil)
+
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = "Projects"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           secret,
+		"provisioning_uri": totp.ProvisioningURI(secret, user.Email, issuer),
+	})
+}
+
+func ConfirmTOTP(c *gin.Context) {
+	var input models.TOTPCodeInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var user models.User
+	if !currentUser(c, &user) {
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication is already enabled"})
+		return
+	}
+
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication setup has not been started"})
+		return
+	}
+
+	if !checkTOTPCode(database.DB, &user, input.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid two-factor authentication code"})
+		return
+	}
+
+	codes, err := generateRecoveryCodes(database.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate recovery codes"})
+		return
+	}
+
+	if err := database.DB.Model(&user).Update("totp_enabled", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't enable two-factor authentication"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.totp.enable", "user", user.ID,
+		gin.H{"totp_enabled": false}, gin.H{"totp_enabled": true}, nil)
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var input models.TOTPCodeInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var user models.User
+	if !currentUser(c, &user) {
+		return
+	}
+
+	if !user.TOTPEnabled || !checkTOTPCode(database.DB, &user, input.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid two-factor authentication code"})
+		return
+	}
+
+	codes, err := generateRecoveryCodes(database.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate recovery codes"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.recovery_codes.regenerate", "user", user.ID, nil, nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func DisableTOTP(c *gin.Context) {
+	var input models.TOTPCodeInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var user models.User
+	if !currentUser(c, &user) {
+		return
+	}
+
+	if config.MFARequiredFor(user.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "two-factor authentication is required for your role"})
+		return
+	}
+
+	if !user.TOTPEnabled || !checkTOTPCode(database.DB, &user, input.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid two-factor authentication code"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{"totp_enabled": false, "totp_secret": "", "totp_last_step": 0}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't disable two-factor authentication"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.totp.disable", "user", user.ID,
+		gin.H{"totp_enabled": true}, gin.H{"totp_enabled": false}, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/oidc"
+	"backend/internal/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func OIDCLogin(c *gin.Context) {
+	if oidc.Default == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": oidc.ErrNotConfigured.Error()})
+		return
+	}
+
+	var loginState models.OIDCLoginState
+
+	for _, value := range []*string{&loginState.State, &loginState.CodeVerifier, &loginState.Nonce} {
+		generated, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't start single sign-on"})
+			return
+		}
+		*value = generated
+	}
+
+	loginState.ExpiresAt = time.Now().Add(config.OIDCLoginStateLifetime)
+
+	authURL, err := oidc.Default.AuthCodeURL(c.Request.Context(), loginState.State, loginState.Nonce, loginState.CodeVerifier)
+	if err != nil {
+		log.Printf("Failed to build OIDC authorization URL: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider is unavailable"})
+		return
+	}
+
+	if err := database.DB.Create(&loginState).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't start single sign-on"})
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func OIDCCallback(c *gin.Context) {
+	if oidc.Default == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": oidc.ErrNotConfigured.Error()})
+		return
+	}
+
+	if errorCode := c.Query("error"); errorCode != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "identity provider returned " + errorCode})
+		return
+	}
+
+	var loginState models.OIDCLoginState
+
+	if err := database.DB.Where("state = ?", c.Query("state")).First(&loginState).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired login state"})
+		return
+	}
+
+	result := database.DB.Unscoped().Delete(&loginState)
+	if result.Error != nil || result.RowsAffected == 0 || time.Now().After(loginState.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired login state"})
+		return
+	}
+
+	identity, err := oidc.Default.Exchange(c.Request.Context(), c.Query("code"), loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		log.Printf("OIDC code exchange failed: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "single sign-on failed"})
+		return
+	}
+
+	var user models.User
+
+	before, err := provisionOIDCUser(database.DB, identity, &user)
+	if err != nil {
+		log.Printf("Failed to provision OIDC user %s: %v", identity.Subject, err)
+		c.JSON(http.StatusConflict, gin.H{"error": "couldn't link identity to an account"})
+		return
+	}
+
+	if before == nil {
+		audit.RecordChange(c, database.DB, "user.oidc.create", "user", user.ID, nil, user.ToSchema(), nil)
+	} else if *before != user.ToSchema() {
+		audit.RecordChange(c, database.DB, "user.oidc.update", "user", user.ID, before, user.ToSchema(), nil)
+	}
+
+	if !user.IsActive {
+		c.JSON(http.StatusForbidden, gin.H{"error": "account is deactivated"})
+		return
+	}
+
+	if startMFALogin(c, &user) {
+		return
+	}
+
+	issueTokens(c, &user)
+}
+
+var errUnverifiedOIDCEmail = errors.New("identity provider has not verified the email of an existing account")
+
+func provisionOIDCUser(db *gorm.DB, identity *oidc.Identity, user *models.User) (*models.UserSchema, error) {
+	subject := oidc.Default.Issuer + "|" + identity.Subject
+	email := strings.ToLower(identity.Email)
+	matchedByEmail := false
+
+	err := db.Where("oidc_subject = ?", subject).First(user).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = db.Where("LOWER(email) = ?", email).First(user).Error
+		matchedByEmail = err == nil
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			*user = models.User{Email: email, Role: config.Member, IsActive: true}
+			err = nil
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var before *models.UserSchema
+	if user.ID != 0 {
+		schema := user.ToSchema()
+		before = &schema
+	}
+
+	if err := applyOIDCIdentity(oidc.Default, identity, user, matchedByEmail, time.Now()); err != nil {
+		return nil, err
+	}
+
+	isNew := user.ID == 0
+	roleChanged := before != nil && before.Role != user.Role
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if roleChanged && before.Role == config.Admin {
+			if err := checkNotLastActiveAdmin(tx, user.ID); errors.Is(err, errLastActiveAdmin) {
+				log.Printf("Keeping user %d as admin: identity provider groups would demote the last active admin", user.ID)
+				user.Role = config.Admin
+				roleChanged = false
+			} else if err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		if roleChanged {
+			if err := auth.RevokeUserRefreshTokens(tx, user.ID); err != nil {
+				return err
+			}
+		}
+		if !isNew {
+			return nil
+		}
+		return createPersonalOrganization(tx, user)
+	})
+
+	return before, err
+}
+
+func applyOIDCIdentity(provider *oidc.Provider, identity *oidc.Identity, user *models.User, matchedByEmail bool, now time.Time) error {
+	if matchedByEmail && !identity.EmailVerified {
+		return errUnverifiedOIDCEmail
+	}
+
+	subject := provider.Issuer + "|" + identity.Subject
+	user.OIDCSubject = &subject
+
+	if identity.GivenName != "" {
+		user.FirstName = identity.GivenName
+	}
+	if identity.FamilyName != "" {
+		user.LastName = identity.FamilyName
+	}
+
+	if role, ok := provider.RoleForGroups(identity.Groups); ok {
+		user.Role = role
+	}
+
+	if identity.EmailVerified && user.EmailVerifiedAt == nil && user.Email == strings.ToLower(identity.Email) {
+		user.EmailVerifiedAt = &now
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/oidc"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApplyOIDCIdentity(t *testing.T) {
+	provider := &oidc.Provider{
+		Issuer:     "https://idp.example.com",
+		GroupRoles: map[string]config.RoleChoice{"admins": config.Admin},
+	}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	verifiedAt := now.Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		user           models.User
+		identity       oidc.Identity
+		matchedByEmail bool
+		wantErr        error
+		wantVerifiedAt *time.Time
+		wantRole       config.RoleChoice
+	}{
+		{
+			name:           "links an existing account by verified email",
+			user:           models.User{Email: "jane@example.com", Role: config.Member},
+			identity:       oidc.Identity{Subject: "1", Email: "Jane@Example.com", EmailVerified: true},
+			matchedByEmail: true,
+			wantVerifiedAt: &now,
+			wantRole:       config.Member,
+		},
+		{
+			name:           "refuses to link an existing account by unverified email",
+			user:           models.User{Email: "jane@example.com", Role: config.Member},
+			identity:       oidc.Identity{Subject: "1", Email: "jane@example.com"},
+			matchedByEmail: true,
+			wantErr:        errUnverifiedOIDCEmail,
+		},
+		{
+			name:     "creates an account without verifying an unverified email",
+			user:     models.User{Email: "jane@example.com", Role: config.Member},
+			identity: oidc.Identity{Subject: "1", Email: "jane@example.com"},
+			wantRole: config.Member,
+		},
+		{
+			name:     "doesn't verify an account whose email differs from the identity",
+			user:     models.User{Email: "jane@corp.example.com", Role: config.Member},
+			identity: oidc.Identity{Subject: "1", Email: "jane@example.com", EmailVerified: true},
+			wantRole: config.Member,
+		},
+		{
+			name:           "keeps an earlier verification",
+			user:           models.User{Email: "jane@example.com", Role: config.Member, EmailVerifiedAt: &verifiedAt},
+			identity:       oidc.Identity{Subject: "1", Email: "jane@example.com", EmailVerified: true},
+			wantVerifiedAt: &verifiedAt,
+			wantRole:       config.Member,
+		},
+		{
+			name:     "maps groups to a role",
+			user:     models.User{Email: "jane@example.com", Role: config.Member},
+			identity: oidc.Identity{Subject: "1", Email: "jane@example.com", Groups: []string{"admins"}},
+			wantRole: config.Admin,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user := test.user
+
+			err := applyOIDCIdentity(provider, &test.identity, &user, test.matchedByEmail, now)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("error = %v, want %v", err, test.wantErr)
+			}
+
+			if test.wantErr != nil {
+				if user.OIDCSubject != nil {
+					t.Error("identity was linked despite the error")
+				}
+				return
+			}
+
+			if user.OIDCSubject == nil || *user.OIDCSubject != "https://idp.example.com|1" {
+				t.Errorf("OIDCSubject = %v", user.OIDCSubject)
+			}
+
+			if (user.EmailVerifiedAt == nil) != (test.wantVerifiedAt == nil) ||
+				(user.EmailVerifiedAt != nil && !user.EmailVerifiedAt.Equal(*test.wantVerifiedAt)) {
+				t.Errorf("EmailVerifiedAt = %v, want %v", user.EmailVerifiedAt, test.wantVerifiedAt)
+			}
+
+			if user.Role != test.wantRole {
+				t.Errorf("Role = %q, want %q", user.Role, test.wantRole)
+			}
+		})
+	}
+}
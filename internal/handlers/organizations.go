@@ -0,0 +1,488 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/permissions"
+	"backend/internal/trash"
+	"backend/internal/utils"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var (
+	organizationSlugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	slugSeparatorPattern    = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+func slugify(value string) string {
+	return strings.Trim(slugSeparatorPattern.ReplaceAllString(strings.ToLower(value), "-"), "-")
+}
+
+func createPersonalOrganization(tx *gorm.DB, user *models.User) error {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if name == "" {
+		name = user.Email
+	}
+
+	organization := models.Organization{
+		Name: name,
+		Slug: fmt.Sprintf("user-%d", user.ID),
+	}
+
+	if err := tx.Create(&organization).Error; err != nil {
+		return err
+	}
+
+	return permissions.SetOrganizationRole(tx, organization.ID, user.ID, config.OrganizationOwner)
+}
+
+func findAccessibleOrganization(c *gin.Context, organization *models.Organization, minRole config.OrganizationRoleChoice) (config.OrganizationRoleChoice, bool) {
+	if err := database.DB.First(organization, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving organization"})
+		}
+		return "", false
+	}
+
+	userID, _ := auth.CurrentUserID(c)
+
+	role, ok := permissions.OrganizationRole(database.DB, organization.ID, userID)
+	if auth.HasRole(c, config.Admin) {
+		return role, true
+	}
+
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return "", false
+	}
+
+	if !role.AtLeast(minRole) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient organization role"})
+		return "", false
+	}
+
+	return role, true
+}
+
+func canGrantOrganizationRole(c *gin.Context, current, role config.OrganizationRoleChoice) bool {
+	if auth.HasRole(c, config.Admin) {
+		return true
+	}
+
+	if role == config.OrganizationOwner || role == config.OrganizationAdmin {
+		return current == config.OrganizationOwner
+	}
+
+	return current.AtLeast(config.OrganizationAdmin)
+}
+
+func isLastOrganizationOwner(organizationID, userID uint) bool {
+	ownerIDs, err := permissions.OrganizationOwnerIDs(database.DB, organizationID)
+	if err != nil {
+		return true
+	}
+
+	return len(ownerIDs) == 1 && ownerIDs[0] == userID
+}
+
+func ReadOrganizations(c *gin.Context) {
+	userID, _ := auth.CurrentUserID(c)
+
+	var memberships []models.OrganizationMember
+
+	if err := database.DB.Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find organizations"})
+		return
+	}
+
+	roles := make(map[uint]config.OrganizationRoleChoice, len(memberships))
+	ids := make([]uint, 0, len(memberships))
+	for _, membership := range memberships {
+		roles[membership.OrganizationID] = membership.Role
+		ids = append(ids, membership.OrganizationID)
+	}
+
+	var organizations []models.Organization
+
+	if err := database.DB.Where("id IN ?", ids).Order("name").Find(&organizations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find organizations"})
+		return
+	}
+
+	serializedOrganizations := []models.OrganizationSchema{}
+	for _, organization := range organizations {
+		serializedOrganizations = append(serializedOrganizations, organization.ToSchema(roles[organization.ID]))
+	}
+
+	c.JSON(http.StatusOK, serializedOrganizations)
+}
+
+func CreateOrganization(c *gin.Context) {
+	var input models.OrganizationInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	if input.Slug == "" {
+		input.Slug = slugify(input.Name)
+	}
+
+	if !organizationSlugPattern.MatchString(input.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slug may only contain lowercase letters, digits and dashes"})
+		return
+	}
+
+	var existing int64
+	database.DB.Model(&models.Organization{}).Where("slug = ?", input.Slug).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slug is already taken"})
+		return
+	}
+
+	userID, _ := auth.CurrentUserID(c)
+
+	organization := models.Organization{Name: input.Name, Slug: input.Slug}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&organization).Error; err != nil {
+			return err
+		}
+		return permissions.SetOrganizationRole(tx, organization.ID, userID, config.OrganizationOwner)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't create organization"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "organization.create", "organization", organization.ID, nil, organization.ToSchema(""), nil)
+
+	c.JSON(http.StatusCreated, organization.ToSchema(config.OrganizationOwner))
+}
+
+func ReadOrganization(c *gin.Context) {
+	var organization models.Organization
+
+	role, ok := findAccessibleOrganization(c, &organization, config.OrganizationMember)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, organization.ToSchema(role))
+}
+
+func UpdateOrganization(c *gin.Context) {
+	var organization models.Organization
+
+	role, ok := findAccessibleOrganization(c, &organization, config.OrganizationAdmin)
+	if !ok {
+		return
+	}
+
+	var input models.OrganizationInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	before := organization.ToSchema("")
+
+	if name := strings.TrimSpace(input.Name); name != "" {
+		organization.Name = name
+	}
+
+	if input.Slug != "" && input.Slug != organization.Slug {
+		if !organizationSlugPattern.MatchString(input.Slug) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "slug may only contain lowercase letters, digits and dashes"})
+			return
+		}
+
+		var existing int64
+		database.DB.Model(&models.Organization{}).Where("slug = ? AND id <> ?", input.Slug, organization.ID).Count(&existing)
+		if existing > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "slug is already taken"})
+			return
+		}
+
+		organization.Slug = input.Slug
+	}
+
+	if err := database.DB.Select("name", "slug").Updates(&organization).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update organization"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "organization.update", "organization", organization.ID, before, organization.ToSchema(""), nil)
+
+	c.JSON(http.StatusOK, organization.ToSchema(role))
+}
+
+func DeleteOrganization(c *gin.Context) {
+	var organization models.Organization
+
+	if _, ok := findAccessibleOrganization(c, &organization, config.OrganizationOwner); !ok {
+		return
+	}
+
+	var projects int64
+	database.DB.Model(&models.Project{}).Where("organization_id = ?", organization.ID).Count(&projects)
+	if projects > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization still has projects"})
+		return
+	}
+
+	var trashedProjectIDs []uint
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Project{}).
+			Where("organization_id = ? AND deleted_at IS NOT NULL", organization.ID).
+			Pluck("id", &trashedProjectIDs).Error; err != nil {
+			return err
+		}
+		if err := trash.PurgeProjects(tx, trashedProjectIDs); err != nil {
+			return err
+		}
+
+		organizationTeamIDs := tx.Model(&models.Team{}).Select("id").Where("organization_id = ?", organization.ID)
+
+		for _, table := range []string{"team_members", "project_teams", "task_teams"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE team_id IN (?)", organizationTeamIDs).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Where("organization_id = ?", organization.ID).Delete(&models.Team{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ?", organization.ID).Delete(&models.OrganizationMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&organization).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't delete organization"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "organization.delete", "organization", organization.ID, organization.ToSchema(""), nil,
+		map[string]interface{}{"purged_projects": len(trashedProjectIDs)})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Organization deleted successfully"})
+}
+
+func SwitchOrganization(c *gin.Context) {
+	var organization models.Organization
+
+	role, ok := findAccessibleOrganization(c, &organization, config.OrganizationMember)
+	if !ok {
+		return
+	}
+
+	claims, ok := c.Value("claims").(*auth.Claims)
+	if !ok || claims.FamilyID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization can only be switched within a login session"})
+		return
+	}
+
+	var user models.User
+	if !currentUser(c, &user) {
+		return
+	}
+
+	if err := auth.SwitchSessionOrganization(database.DB, claims.FamilyID, organization.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't switch organization"})
+		return
+	}
+
+	var accessToken string
+
+	if err := auth.GenerateToken(&accessToken, &user, claims.FamilyID, organization.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate token"})
+		return
+	}
+
+	if err := auth.RevokeAccessToken(database.DB, claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't revoke token"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "organization.switch", "session", 0,
+		gin.H{"organization_id": claims.OrganizationID}, gin.H{"organization_id": organization.ID}, gin.H{"session_id": claims.FamilyID})
+
+	c.JSON(http.StatusOK, gin.H{"access": accessToken, "organization": organization.ToSchema(role)})
+}
+
+func ReadOrganizationMembers(c *gin.Context) {
+	var organization models.Organization
+
+	if _, ok := findAccessibleOrganization(c, &organization, config.OrganizationMember); !ok {
+		return
+	}
+
+	var memberships []models.OrganizationMember
+	if err := database.DB.Where("organization_id = ?", organization.ID).Find(&memberships).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find organization members"})
+		return
+	}
+
+	roles := make(map[uint]config.OrganizationRoleChoice, len(memberships))
+	ids := make([]uint, 0, len(memberships))
+	for _, membership := range memberships {
+		roles[membership.UserID] = membership.Role
+		ids = append(ids, membership.UserID)
+	}
+
+	var users []models.User
+	if err := database.DB.Where("id IN ?", ids).Order("id").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find organization members"})
+		return
+	}
+
+	members := []models.OrganizationMemberSchema{}
+	for _, user := range users {
+		members = append(members, models.OrganizationMemberSchema{User: user.ToSchema(), Role: roles[user.ID]})
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+func SetOrganizationMember(c *gin.Context) {
+	var organization models.Organization
+
+	actorRole, ok := findAccessibleOrganization(c, &organization, config.OrganizationAdmin)
+	if !ok {
+		return
+	}
+
+	var input models.OrganizationMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	if !input.Role.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown organization role"})
+		return
+	}
+
+	var membership models.OrganizationMember
+	if err := database.DB.Where("organization_id = ? AND user_id = ?", organization.ID, c.Param("user_id")).First(&membership).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization member not found, invite the user to a project instead"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving organization member"})
+		}
+		return
+	}
+
+	current := membership.Role
+
+	if !canGrantOrganizationRole(c, actorRole, input.Role) || !canGrantOrganizationRole(c, actorRole, current) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient organization role"})
+		return
+	}
+
+	if current == config.OrganizationOwner && input.Role != config.OrganizationOwner && isLastOrganizationOwner(organization.ID, membership.UserID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization must have at least one owner"})
+		return
+	}
+
+	if err := permissions.SetOrganizationRole(database.DB, organization.ID, membership.UserID, input.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update organization member"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "organization.member.set", "organization", organization.ID,
+		gin.H{"role": current}, gin.H{"role": input.Role}, gin.H{"user_id": membership.UserID})
+
+	c.JSON(http.StatusOK, models.OrganizationMembershipSchema{UserID: membership.UserID, Role: input.Role})
+}
+
+func RemoveOrganizationMember(c *gin.Context) {
+	var organization models.Organization
+
+	actorRole, ok := findAccessibleOrganization(c, &organization, config.OrganizationMember)
+	if !ok {
+		return
+	}
+
+	var membership models.OrganizationMember
+	if err := database.DB.Where("organization_id = ? AND user_id = ?", organization.ID, c.Param("user_id")).First(&membership).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization member not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving organization member"})
+		}
+		return
+	}
+
+	userID, _ := auth.CurrentUserID(c)
+
+	if membership.UserID != userID && !canGrantOrganizationRole(c, actorRole, membership.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient organization role"})
+		return
+	}
+
+	if membership.Role == config.OrganizationOwner && isLastOrganizationOwner(organization.ID, membership.UserID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "organization must have at least one owner"})
+		return
+	}
+
+	organizationProjectIDs := database.DB.Model(&models.Project{}).Select("id").Where("organization_id = ?", organization.ID)
+
+	var ownedProjects int64
+	database.DB.Model(&models.ProjectUser{}).
+		Where("user_id = ? AND role = ? AND project_id IN (?)", membership.UserID, config.ProjectOwner, organizationProjectIDs).
+		Where("NOT EXISTS (SELECT 1 FROM project_users other WHERE other.project_id = project_users.project_id AND other.role = ? AND other.user_id <> ?)", config.ProjectOwner, membership.UserID).
+		Count(&ownedProjects)
+
+	if ownedProjects > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "member is the only owner of a project in this organization"})
+		return
+	}
+
+	organizationTeamIDs := database.DB.Model(&models.Team{}).Select("id").Where("organization_id = ?", organization.ID)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND project_id IN (?)", membership.UserID, organizationProjectIDs).Delete(&models.ProjectUser{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM team_members WHERE user_id = ? AND team_id IN (?)", membership.UserID, organizationTeamIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Team{}).Where("lead_id = ? AND organization_id = ?", membership.UserID, organization.ID).Update("lead_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("organization_id = ? AND user_id = ?", organization.ID, membership.UserID).Delete(&models.OrganizationMember{}).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't remove organization member"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "organization.member.remove", "organization", organization.ID,
+		gin.H{"role": membership.Role}, nil, gin.H{"user_id": membership.UserID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Organization member removed successfully"})
+}
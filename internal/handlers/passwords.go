@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/mailer"
+	"backend/internal/models"
+	"backend/internal/password"
+	"backend/internal/utils"
+	"backend/internal/validators"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+var errResetTokenUsed = errors.New("reset token has already been used")
+
+func sendPasswordResetEmail(db *gorm.DB, user *models.User) error {
+	rawToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		resetToken := models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: utils.HashToken(rawToken),
+			ExpiresAt: time.Now().Add(config.PasswordResetTokenLifetime),
+		}
+
+		return tx.Create(&resetToken).Error
+	})
+
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/password/reset?token=%s", os.Getenv("APP_URL"), rawToken)
+
+	return mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"Hello, %s!\n\nTo reset your password open the link below. It expires in %s.\n\n%s\n\nIf you didn't request a password reset, just ignore this email.\n",
+			user.FirstName, config.PasswordResetTokenLifetime, link,
+		),
+	})
+}
+
+func ForgotPassword(c *gin.Context) {
+	var input models.ForgotPasswordInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var user models.User
+
+	if err := database.DB.Where("email = ?", input.Email).First(&user).Error; err == nil {
+		if err := sendPasswordResetEmail(database.DB, &user); err != nil {
+			log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists, a password reset link has been sent"})
+}
+
+func ResetPassword(c *gin.Context) {
+	var input models.ResetPasswordInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var resetToken models.PasswordResetToken
+
+	err := database.DB.
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", utils.HashToken(input.Token), time.Now()).
+		First(&resetToken).Error
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired reset token"})
+		return
+	}
+
+	var user models.User
+
+	if err := database.DB.First(&user, resetToken.UserID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired reset token"})
+		return
+	}
+
+	validationErrors := validators.ValidateNewPassword(database.DB, &user, input.Password, input.PasswordConfirm)
+
+	if len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, validators.ErrorResponse{Details: validationErrors})
+		return
+	}
+
+	hashedPassword, err := password.Hash(input.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't hash password"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&resetToken).Where("used_at IS NULL").Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errResetTokenUsed
+		}
+
+		if err := password.Remember(tx, user.ID, user.Password); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", resetToken.UserID).Updates(map[string]interface{}{
+			"password":                hashedPassword,
+			"password_reset_required": false,
+		}).Error; err != nil {
+			return err
+		}
+
+		return auth.RevokeUserRefreshTokens(tx, resetToken.UserID)
+	})
+
+	if err != nil {
+		if errors.Is(err, errResetTokenUsed) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired reset token"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't reset password"})
+		}
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.password.reset", "user", user.ID,
+		gin.H{"password": user.Password, "password_reset_required": user.PasswordResetRequired},
+		gin.H{"password": hashedPassword, "password_reset_required": false}, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset successfully"})
+}
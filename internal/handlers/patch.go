@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"backend/internal/patch"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func bindPatch(c *gin.Context, document interface{}, target interface{}) bool {
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't encode document"})
+		return false
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "couldn't read request body"})
+		return false
+	}
+
+	patched, err := patch.Apply(c.GetHeader("Content-Type"), encoded, body)
+	if err != nil {
+		if errors.Is(err, patch.ErrUnsupportedContentType) {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "use " + patch.MergePatchContentType + " or " + patch.JSONPatchContentType})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,431 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/mailer"
+	"backend/internal/models"
+	"backend/internal/password"
+	"backend/internal/utils"
+	"backend/internal/validators"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func checkCurrentPassword(user *models.User, plainPassword string) bool {
+	if user.Password == "" {
+		return false
+	}
+
+	ok, _, err := password.Verify(user.Password, plainPassword)
+	return err == nil && ok
+}
+
+func UpdateProfile(c *gin.Context) {
+	var input models.ProfileUpdateInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var user models.User
+	if !currentUser(c, &user) {
+		return
+	}
+
+	before := user.ToSchema()
+	validationErrors := make(map[string]string)
+
+	if input.FirstName != nil {
+		if *input.FirstName == "" {
+			validationErrors["first_name"] = "Поле 'first_name' обязательно"
+		}
+		user.FirstName = *input.FirstName
+	}
+
+	if input.LastName != nil {
+		if *input.LastName == "" {
+			validationErrors["last_name"] = "Поле 'last_name' обязательно"
+		}
+		user.LastName = *input.LastName
+	}
+
+	if input.BirthDate != nil {
+		if err := utils.ParseDateToTime(*input.BirthDate, &user.BirthDate); err != nil {
+			validationErrors["birth_date"] = "incorrect type of birth date"
+		}
+	}
+
+	if input.Gender != nil {
+		if *input.Gender != config.Male && *input.Gender != config.Female {
+			validationErrors["gender"] = "incorrect gender"
+		}
+		user.Gender = *input.Gender
+	}
+
+	if len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, validators.ErrorResponse{Details: validationErrors})
+		return
+	}
+
+	err := database.DB.Model(&user).Select("first_name", "last_name", "birth_date", "gender").Updates(&user).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update profile"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.profile.update", "user", user.ID, before, user.ToSchema(), nil)
+
+	c.JSON(http.StatusOK, user.ToSchema())
+}
+
+func ChangePassword(c *gin.Context) {
+	var input models.ChangePasswordInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var user models.User
+	if !currentUser(c, &user) {
+		return
+	}
+
+	if !checkCurrentPassword(&user, input.CurrentPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "current password is incorrect"})
+		return
+	}
+
+	validationErrors := validators.ValidateNewPassword(database.DB, &user, input.Password, input.PasswordConfirm)
+
+	if len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, validators.ErrorResponse{Details: validationErrors})
+		return
+	}
+
+	hashedPassword, err := password.Hash(input.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't hash password"})
+		return
+	}
+
+	familyID := ""
+	if claims, ok := c.Value("claims").(*auth.Claims); ok {
+		familyID = claims.FamilyID
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := password.Remember(tx, user.ID, user.Password); err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+		return auth.RevokeOtherFamilies(tx, user.ID, familyID)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't change password"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.password.change", "user", user.ID,
+		gin.H{"password": user.Password}, gin.H{"password": hashedPassword}, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+func ChangeEmail(c *gin.Context) {
+	var input models.ChangeEmailInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var user models.User
+	if !currentUser(c, &user) {
+		return
+	}
+
+	if !checkCurrentPassword(&user, input.Password) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "current password is incorrect"})
+		return
+	}
+
+	if message := validators.ValidateEmail(input.Email); message != "" {
+		c.JSON(http.StatusBadRequest, validators.ErrorResponse{Details: map[string]string{"email": message}})
+		return
+	}
+
+	var taken int64
+	database.DB.Model(&models.User{}).Where("LOWER(email) = ?", strings.ToLower(input.Email)).Count(&taken)
+
+	if taken > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email is already in use"})
+		return
+	}
+
+	nonce, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate token"})
+		return
+	}
+
+	var token string
+
+	claims := auth.PurposeClaims{UserID: user.ID, Email: input.Email, Purpose: auth.EmailChangePurpose}
+	claims.ID = nonce
+
+	if err := auth.GeneratePurposeToken(&token, claims, config.EmailVerificationTokenLifetime); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate token"})
+		return
+	}
+
+	if err := database.DB.Model(&user).Update("email_change_nonce", utils.HashToken(nonce)).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate token"})
+		return
+	}
+
+	link := fmt.Sprintf("%s/users/profile/email/confirm?token=%s", os.Getenv("APP_URL"), token)
+
+	err = mailer.Send(mailer.Message{
+		To:      input.Email,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf(
+			"Hello, %s!\n\nPlease confirm your new email address by opening the link below. It expires in %s.\n\n%s\n",
+			user.FirstName, config.EmailVerificationTokenLifetime, link,
+		),
+	})
+
+	if err != nil {
+		log.Printf("Failed to send email change confirmation to user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't send confirmation email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Confirmation link has been sent to the new email address"})
+}
+
+func ConfirmEmailChange(c *gin.Context) {
+	var claims auth.PurposeClaims
+
+	if err := auth.ParsePurposeToken(c.Query("token"), auth.EmailChangePurpose, &claims); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+
+	if err := database.DB.First(&user, claims.UserID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	nonceHash := utils.HashToken(claims.ID)
+
+	if claims.ID == "" || user.EmailChangeNonce != nonceHash {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	previousEmail := user.Email
+	before := user.ToSchema()
+
+	now := time.Now()
+
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND email_change_nonce = ?", user.ID, nonceHash).
+		Updates(map[string]interface{}{"email": claims.Email, "email_verified_at": now, "email_change_nonce": ""})
+
+	if result.Error != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email is already in use"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	user.Email = claims.Email
+	user.EmailVerifiedAt = &now
+	user.EmailChangeNonce = ""
+
+	if previousEmail != claims.Email {
+		audit.RecordChange(c, database.DB, "user.email.change", "user", user.ID, before, user.ToSchema(), nil)
+
+		err := mailer.Send(mailer.Message{
+			To:      previousEmail,
+			Subject: "Your email address was changed",
+			Body:    fmt.Sprintf("Hello, %s!\n\nThe email address of your account was changed to %s.\n", user.FirstName, claims.Email),
+		})
+
+		if err != nil {
+			log.Printf("Failed to notify user %d about email change: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, user.ToSchema())
+}
+
+func deleteUserAccount(db *gorm.DB, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := checkNotLastActiveAdmin(tx, userID); err != nil {
+			return err
+		}
+
+		var ownedProjectIDs []uint
+
+		if err := tx.Model(&models.ProjectUser{}).
+			Where("user_id = ? AND role = ?", userID, config.ProjectOwner).
+			Pluck("project_id", &ownedProjectIDs).Error; err != nil {
+			return err
+		}
+
+		for _, projectID := range ownedProjectIDs {
+			var owners int64
+			tx.Model(&models.ProjectUser{}).Where("project_id = ? AND role = ? AND user_id <> ?", projectID, config.ProjectOwner, userID).Count(&owners)
+
+			if owners > 0 {
+				continue
+			}
+
+			var successor models.ProjectUser
+
+			err := tx.Where("project_id = ? AND user_id <> ?", projectID, userID).
+				Order(fmt.Sprintf("CASE role WHEN '%s' THEN 0 WHEN '%s' THEN 1 ELSE 2 END, created_at", config.ProjectMaintainer, config.ProjectContributor)).
+				First(&successor).Error
+
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Model(&models.ProjectUser{}).
+				Where("project_id = ? AND user_id = ?", projectID, successor.UserID).
+				Update("role", config.ProjectOwner).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.ProjectUser{}).Error; err != nil {
+			return err
+		}
+
+		var ownedOrganizationIDs []uint
+
+		if err := tx.Model(&models.OrganizationMember{}).
+			Where("user_id = ? AND role = ?", userID, config.OrganizationOwner).
+			Pluck("organization_id", &ownedOrganizationIDs).Error; err != nil {
+			return err
+		}
+
+		for _, organizationID := range ownedOrganizationIDs {
+			var owners int64
+			tx.Model(&models.OrganizationMember{}).Where("organization_id = ? AND role = ? AND user_id <> ?", organizationID, config.OrganizationOwner, userID).Count(&owners)
+
+			if owners > 0 {
+				continue
+			}
+
+			var successor models.OrganizationMember
+
+			err := tx.Where("organization_id = ? AND user_id <> ?", organizationID, userID).
+				Order(fmt.Sprintf("CASE role WHEN '%s' THEN 0 ELSE 1 END, created_at", config.OrganizationAdmin)).
+				First(&successor).Error
+
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Model(&models.OrganizationMember{}).
+				Where("organization_id = ? AND user_id = ?", organizationID, successor.UserID).
+				Update("role", config.OrganizationOwner).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.OrganizationMember{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM team_members WHERE user_id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Team{}).Where("lead_id = ?", userID).Update("lead_id", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM task_users WHERE user_id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{
+			&models.RefreshToken{},
+			&models.PersonalAccessToken{},
+			&models.PasswordResetToken{},
+			&models.RecoveryCode{},
+			&models.Session{},
+			&models.PasswordHistory{},
+		} {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().Delete(&models.User{}, userID).Error
+	})
+}
+
+func DeleteAccount(c *gin.Context) {
+	var input models.DeleteAccountInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var user models.User
+	if !currentUser(c, &user) {
+		return
+	}
+
+	if user.Password != "" && !checkCurrentPassword(&user, input.Password) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "current password is incorrect"})
+		return
+	}
+
+	err := deleteUserAccount(database.DB, user.ID)
+
+	if errors.Is(err, errLastActiveAdmin) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot delete the last admin"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't delete account"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.delete", "user", user.ID, user.ToSchema(), nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted successfully"})
+}
@@ -1,9 +1,17 @@
 package handlers
 
 import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
 	"backend/internal/database"
 	"backend/internal/models"
+	"backend/internal/pagination"
+	"backend/internal/permissions"
+	"backend/internal/trash"
 	"backend/internal/utils"
+	"backend/internal/validators"
+	"backend/internal/workflow"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -18,20 +26,60 @@ type UserHandler struct {
 func (u *UserHandler) findProjectByID(c *gin.Context) (*models.Project, error) {
 	var project models.Project
 	id := c.Param("id")
-	if err := u.DB.Preload("Executors").First(&project, id).Error; err != nil {
+	organizationID, _ := auth.CurrentOrganizationID(c)
+	if err := u.DB.Preload("Executors").Preload("Teams").Where("organization_id = ?", organizationID).First(&project, id).Error; err != nil {
 		return nil, err
 	}
 	return &project, nil
 }
 
-func (u *UserHandler) findUsersByID(ids []int) ([]models.User, error) {
+func (u *UserHandler) findAccessibleProject(c *gin.Context, minRole config.ProjectRoleChoice) (*models.Project, bool) {
+	project, err := u.findProjectByID(c)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving project"})
+		}
+		return nil, false
+	}
+
+	if auth.HasRole(c, config.Admin) {
+		return project, true
+	}
+
+	userID, _ := auth.CurrentUserID(c)
+
+	role, ok := permissions.ProjectRole(u.DB, project.ID, userID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return nil, false
+	}
+
+	if !role.AtLeast(minRole) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient project role"})
+		return nil, false
+	}
+
+	return project, true
+}
+
+func (u *UserHandler) findUsersByID(organizationID uint, ids []int) ([]models.User, error) {
 	var users []models.User
-	if err := u.DB.Where("id IN ?", ids).Find(&users).Error; err != nil {
+	if err := u.DB.Where("id IN ? AND id IN (?)", ids, permissions.OrganizationMemberIDs(u.DB, organizationID)).Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
+func (u *UserHandler) findTeamsByID(organizationID uint, ids []int) ([]models.Team, error) {
+	var teams []models.Team
+	if err := u.DB.Where("id IN ? AND organization_id = ?", ids, organizationID).Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
 func (u *UserHandler) ConvertAllProjectsToSchema(projects []models.Project) []models.ProjectSchema {
 	var serializedProjects []models.ProjectSchema
 
@@ -49,12 +97,25 @@ func (u *UserHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
-	users, err := u.findUsersByID(input.Executors)
+	if err := validators.ValidateTitle(input.Title); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organizationID, _ := auth.CurrentOrganizationID(c)
+
+	users, err := u.findUsersByID(organizationID, input.Executors)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Couldn't find users"})
 		return
 	}
 
+	teams, err := u.findTeamsByID(organizationID, input.Teams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Couldn't find teams"})
+		return
+	}
+
 	var ParsedStartedAt time.Time
 	if err := utils.ParseDateToTime(input.StartedAt, &ParsedStartedAt); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect type of started_at"})
@@ -79,52 +140,105 @@ func (u *UserHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
+	if input.Status == "" {
+		input.Status = config.Created
+	}
+
+	if err := workflow.CheckInitial(input.Status); err != nil {
+		respondStatusError(c, err)
+		return
+	}
+
 	project := models.Project{
-		Title:       input.Title,
-		Description: input.Description,
-		StartedAt:   ParsedStartedAt,
-		Deadline:    ParsedDeadline,
-		Status:      input.Status,
-		Executors:   users,
-		Tasks:       input.Tasks,
+		OrganizationID: organizationID,
+		Title:          input.Title,
+		Description:    input.Description,
+		StartedAt:      ParsedStartedAt,
+		Deadline:       ParsedDeadline,
+		Status:         input.Status,
+		Executors:      users,
+		Teams:          teams,
+	}
+
+	userID, _ := auth.CurrentUserID(c)
+
+	err = u.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&project).Error; err != nil {
+			return err
+		}
+		if err := workflow.Record(tx, "project", project.ID, project.ID, "", project.Status, &userID); err != nil {
+			return err
+		}
+		return permissions.SetProjectRole(tx, project.ID, userID, config.ProjectOwner)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't create project"})
+		return
 	}
 
-	database.DB.Create(&project)
+	audit.RecordChange(c, u.DB, "project.create", "project", project.ID, nil, project.ToSchema(), nil)
 
 	c.JSON(http.StatusCreated, project.ToSchema())
 }
 
 func (u *UserHandler) ReadProjects(c *gin.Context) {
+	page, err := pagination.Parse(c, projectSortFields, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organizationID, _ := auth.CurrentOrganizationID(c)
+
+	query := u.DB.Model(&models.Project{}).Where("organization_id = ?", organizationID)
+
+	if !auth.HasRole(c, config.Admin) {
+		userID, _ := auth.CurrentUserID(c)
+		query = query.Where("id IN (?)", permissions.MemberProjectIDs(u.DB, userID))
+	}
+
+	query, ok := applyListFilters(c, query, projectListFilters)
+	if !ok {
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't count projects"})
+		return
+	}
+
 	var projects []models.Project
-	if err := u.DB.Preload("Executors").Find(&projects).Error; err != nil {
+	if err := page.Apply(query.Preload("Executors").Preload("Teams")).Find(&projects).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find projects"})
 		return
 	}
 
-	c.JSON(http.StatusOK, u.ConvertAllProjectsToSchema(projects))
+	projects, nextCursor, err := pagination.Trim(u.DB, page, projects)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't paginate projects"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ProjectListSchema{
+		Results:    u.ConvertAllProjectsToSchema(projects),
+		NextCursor: nextCursor,
+		Total:      total,
+	})
 }
 
 func (u *UserHandler) ReadProject(c *gin.Context) {
-	project, err := u.findProjectByID(c)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving project"})
-		}
+	project, ok := u.findAccessibleProject(c, config.ProjectReadOnly)
+	if !ok {
 		return
 	}
 	c.JSON(http.StatusOK, project.ToSchema())
 }
 
 func (u *UserHandler) UpdateProject(c *gin.Context) {
-	project, err := u.findProjectByID(c)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving project"})
-		}
+	project, ok := u.findAccessibleProject(c, config.ProjectMaintainer)
+	if !ok {
 		return
 	}
 
@@ -134,6 +248,41 @@ func (u *UserHandler) UpdateProject(c *gin.Context) {
 		return
 	}
 
+	u.saveProject(c, project, input, true)
+}
+
+func (u *UserHandler) PatchProject(c *gin.Context) {
+	project, ok := u.findAccessibleProject(c, config.ProjectMaintainer)
+	if !ok {
+		return
+	}
+
+	var input models.ProjectUpdateSchema
+	if !bindPatch(c, projectUpdateDocument(project), &input) {
+		return
+	}
+
+	u.saveProject(c, project, input, false)
+}
+
+func projectUpdateDocument(project *models.Project) models.ProjectUpdateSchema {
+	return models.ProjectUpdateSchema{
+		Title:       project.Title,
+		Description: project.Description,
+		StartedAt:   project.StartedAt.UTC().Format("02.01.2006"),
+		Deadline:    project.Deadline.UTC().Format("02.01.2006"),
+		Status:      project.Status,
+	}
+}
+
+func (u *UserHandler) saveProject(c *gin.Context, project *models.Project, input models.ProjectUpdateSchema, replace bool) {
+	before := project.ToSchema()
+
+	if err := validators.ValidateTitle(input.Title); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var ParsedStartedAt time.Time
 	if err := utils.ParseDateToTime(input.StartedAt, &ParsedStartedAt); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect type of started_at"})
@@ -148,7 +297,10 @@ func (u *UserHandler) UpdateProject(c *gin.Context) {
 
 	currentTime := time.Now()
 
-	if currentTime.After(ParsedStartedAt) || currentTime.After(ParsedDeadline) {
+	startedAtChanged := replace || !ParsedStartedAt.Equal(project.StartedAt)
+	deadlineChanged := replace || !ParsedDeadline.Equal(project.Deadline)
+
+	if (startedAtChanged && currentTime.After(ParsedStartedAt)) || (deadlineChanged && currentTime.After(ParsedDeadline)) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "current date cannot be later than started_at or deadline"})
 		return
 	}
@@ -158,10 +310,13 @@ func (u *UserHandler) UpdateProject(c *gin.Context) {
 		return
 	}
 
-	users, err := u.findUsersByID(input.Executors)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Couldn't find users"})
-		return
+	previousStatus := project.Status
+
+	if input.Status != "" && input.Status != previousStatus {
+		if err := workflow.Check(u.DB, project.ID, previousStatus, input.Status); err != nil {
+			respondStatusError(c, err)
+			return
+		}
 	}
 
 	project.Title = input.Title
@@ -171,27 +326,46 @@ func (u *UserHandler) UpdateProject(c *gin.Context) {
 	if input.Status != "" {
 		project.Status = input.Status
 	}
-	project.Executors = users
-	project.Tasks = input.Tasks
 
-	u.DB.Save(&project)
+	actorID, _ := auth.CurrentUserID(c)
+
+	err := u.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Executors", "Teams", "Tasks").Save(project).Error; err != nil {
+			return err
+		}
+		if project.Status != previousStatus {
+			return workflow.Record(tx, "project", project.ID, project.ID, previousStatus, project.Status, &actorID)
+		}
+		return nil
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update project"})
+		return
+	}
+
+	audit.RecordChange(c, u.DB, "project.update", "project", project.ID, before, project.ToSchema(), nil)
 
 	c.JSON(http.StatusOK, project.ToSchema())
 }
 
 func (u *UserHandler) DeleteProject(c *gin.Context) {
-	project, err := u.findProjectByID(c)
+	project, ok := u.findAccessibleProject(c, config.ProjectOwner)
+	if !ok {
+		return
+	}
+
+	err := u.DB.Transaction(func(tx *gorm.DB) error {
+		return trash.DeleteProject(tx, project)
+	})
+
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving project"})
-		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't delete project"})
 		return
 	}
-	c.JSON(http.StatusOK, project.ToSchema())
 
-	u.DB.Delete(&project)
+	audit.RecordChange(c, u.DB, "project.delete", "project", project.ID, project.ToSchema(), nil, nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
 }
 
@@ -206,10 +380,28 @@ func ProjectViewSet(c *gin.Context) {
 			userHandler.ReadProjects(c)
 		}
 	case "POST":
+		if !auth.HasRole(c, config.Admin, config.Manager) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only managers can create projects"})
+			return
+		}
 		userHandler.CreateProject(c)
 	case "PUT":
+		if !auth.HasRole(c, config.Admin, config.Manager, config.Member) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "viewers cannot update projects"})
+			return
+		}
 		userHandler.UpdateProject(c)
+	case "PATCH":
+		if !auth.HasRole(c, config.Admin, config.Manager, config.Member) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "viewers cannot update projects"})
+			return
+		}
+		userHandler.PatchProject(c)
 	case "DELETE":
+		if !auth.HasRole(c, config.Admin, config.Manager) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only managers can delete projects"})
+			return
+		}
 		userHandler.DeleteProject(c)
 	default:
 		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "method now allowed"})
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/database"
+	"backend/internal/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+func currentSessionID(c *gin.Context) string {
+	if claims, ok := c.Value("claims").(*auth.Claims); ok {
+		return claims.FamilyID
+	}
+	return ""
+}
+
+func ReadSessions(c *gin.Context) {
+	userID, _ := auth.CurrentUserID(c)
+
+	var sessions []models.Session
+
+	if err := database.DB.Where("user_id = ? AND revoked_at IS NULL", userID).Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find sessions"})
+		return
+	}
+
+	currentID := currentSessionID(c)
+
+	serializedSessions := []models.SessionSchema{}
+	for _, session := range sessions {
+		serializedSessions = append(serializedSessions, session.ToSchema(currentID))
+	}
+
+	c.JSON(http.StatusOK, serializedSessions)
+}
+
+func RevokeSession(c *gin.Context) {
+	userID, _ := auth.CurrentUserID(c)
+
+	var session models.Session
+
+	if err := database.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", c.Param("id"), userID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving session"})
+		}
+		return
+	}
+
+	if err := auth.RevokeFamily(database.DB, session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't terminate session"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "session.revoke", "session", 0, session.ToSchema(""), nil, gin.H{"session_id": session.ID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session terminated successfully"})
+}
+
+func RevokeOtherSessions(c *gin.Context) {
+	userID, _ := auth.CurrentUserID(c)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return auth.RevokeOtherFamilies(tx, userID, currentSessionID(c))
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't terminate sessions"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "session.revoke_others", "user", userID, nil, nil, gin.H{"kept_session_id": currentSessionID(c)})
+
+	c.JSON(http.StatusOK, gin.H{"message": "All other sessions terminated successfully"})
+}
@@ -1,14 +1,21 @@
 package handlers
 
 import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
 	"backend/internal/database"
 	"backend/internal/models"
+	"backend/internal/pagination"
+	"backend/internal/permissions"
 	"backend/internal/utils"
 	"backend/internal/validators"
+	"backend/internal/workflow"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -19,20 +26,76 @@ type TaskHandler struct {
 func (T *TaskHandler) findTaskByID(c *gin.Context) (*models.Task, error) {
 	var task models.Task
 	id := c.Param("id")
-	if err := T.DB.Preload("Executors").First(&task, id).Error; err != nil {
+	organizationID, _ := auth.CurrentOrganizationID(c)
+	if err := T.DB.Preload("Executors").Preload("Teams").Where("organization_id = ?", organizationID).First(&task, id).Error; err != nil {
 		return nil, err
 	}
 	return &task, nil
 }
 
-func (T *TaskHandler) findUsersByID(ids []int) ([]models.User, error) {
+func (T *TaskHandler) authorizeProject(c *gin.Context, projectID uint, minRole config.ProjectRoleChoice, notFound string) bool {
+	if auth.HasRole(c, config.Admin) {
+		return true
+	}
+
+	userID, _ := auth.CurrentUserID(c)
+
+	role, ok := permissions.ProjectRole(T.DB, projectID, userID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return false
+	}
+
+	if !role.AtLeast(minRole) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient project role"})
+		return false
+	}
+
+	return true
+}
+
+func (T *TaskHandler) findAccessibleTask(c *gin.Context, minRole config.ProjectRoleChoice) (*models.Task, bool) {
+	task, err := T.findTaskByID(c)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving task"})
+		}
+		return nil, false
+	}
+
+	if !T.authorizeTask(c, task, minRole) {
+		return nil, false
+	}
+
+	return task, true
+}
+
+func (T *TaskHandler) authorizeTask(c *gin.Context, task *models.Task, minRole config.ProjectRoleChoice) bool {
+	if userID, _ := auth.CurrentUserID(c); config.ProjectContributor.AtLeast(minRole) && permissions.IsTaskTeamMember(T.DB, task.ID, userID) {
+		return true
+	}
+
+	return T.authorizeProject(c, task.ProjectID, minRole, "Task not found")
+}
+
+func (T *TaskHandler) findUsersByID(organizationID uint, ids []int) ([]models.User, error) {
 	var users []models.User
-	if err := T.DB.Where("id IN ?", ids).Find(&users).Error; err != nil {
+	if err := T.DB.Where("id IN ? AND id IN (?)", ids, permissions.OrganizationMemberIDs(T.DB, organizationID)).Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
+func (T *TaskHandler) findTeamsByID(organizationID uint, ids []int) ([]models.Team, error) {
+	var teams []models.Team
+	if err := T.DB.Where("id IN ? AND organization_id = ?", ids, organizationID).Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
 func (T *TaskHandler) ConvertAllTasksToSchema(tasks []models.Task) []models.TaskSchema {
 	var serializedTasks []models.TaskSchema
 
@@ -50,15 +113,36 @@ func (T *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
-	users, err := T.findUsersByID(input.Executors)
+	if err := validators.ValidateTitle(input.Title); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organizationID, _ := auth.CurrentOrganizationID(c)
+
+	users, err := T.findUsersByID(organizationID, input.Executors)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Couldn't find users"})
 		return
 	}
 
 	var project models.Project
-	if err := T.DB.First(&project, "id = ?", input.ProjectID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find project"})
+	if err := T.DB.Where("organization_id = ?", organizationID).First(&project, "id = ?", input.ProjectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find project"})
+		}
+		return
+	}
+
+	if !T.authorizeProject(c, project.ID, config.ProjectContributor, "Project not found") {
+		return
+	}
+
+	teams, err := T.findTeamsByID(organizationID, input.Teams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Couldn't find teams"})
 		return
 	}
 
@@ -73,52 +157,111 @@ func (T *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
+	if input.Status == "" {
+		input.Status = config.Created
+	}
+
+	if err := workflow.CheckInitial(input.Status); err != nil {
+		respondStatusError(c, err)
+		return
+	}
+
 	task := models.Task{
-		Title:       input.Title,
-		Description: input.Description,
-		Deadline:    ParsedDeadline,
-		Status:      input.Status,
-		ProjectID:   project.ID,
-		Executors:   users,
+		OrganizationID: project.OrganizationID,
+		Title:          input.Title,
+		Description:    input.Description,
+		Deadline:       ParsedDeadline,
+		Status:         input.Status,
+		ProjectID:      project.ID,
+		Executors:      users,
+		Teams:          teams,
 	}
 
-	T.DB.Create(&task)
+	actorID, _ := auth.CurrentUserID(c)
+
+	err = T.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		return workflow.Record(tx, "task", task.ID, task.ProjectID, "", task.Status, &actorID)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't create task"})
+		return
+	}
+
+	audit.RecordChange(c, T.DB, "task.create", "task", task.ID, nil, task.ToSchema(), nil)
 
 	c.JSON(http.StatusCreated, task.ToSchema())
 }
 
 func (T *TaskHandler) ReadTasks(c *gin.Context) {
+	page, err := pagination.Parse(c, taskSortFields, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organizationID, _ := auth.CurrentOrganizationID(c)
+
+	query := T.DB.Model(&models.Task{}).Where("organization_id = ?", organizationID)
+
+	if !auth.HasRole(c, config.Admin) {
+		userID, _ := auth.CurrentUserID(c)
+		query = query.Where("project_id IN (?) OR id IN (?)", permissions.MemberProjectIDs(T.DB, userID), permissions.TeamTaskIDs(T.DB, userID))
+	}
+
+	if projectID := c.Query("project_id"); projectID != "" {
+		parsedProjectID, err := strconv.ParseUint(projectID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect project_id"})
+			return
+		}
+		query = query.Where("project_id = ?", parsedProjectID)
+	}
+
+	query, ok := applyListFilters(c, query, taskListFilters)
+	if !ok {
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't count tasks"})
+		return
+	}
+
 	var tasks []models.Task
+	if err := page.Apply(query.Preload("Executors").Preload("Teams")).Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find tasks"})
+		return
+	}
 
-	if err := T.DB.Preload("Executors").Find(&tasks).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find projects"})
+	tasks, nextCursor, err := pagination.Trim(T.DB, page, tasks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't paginate tasks"})
 		return
 	}
 
-	c.JSON(http.StatusOK, T.ConvertAllTasksToSchema(tasks))
+	c.JSON(http.StatusOK, models.TaskListSchema{
+		Results:    T.ConvertAllTasksToSchema(tasks),
+		NextCursor: nextCursor,
+		Total:      total,
+	})
 }
 
 func (T *TaskHandler) ReadTask(c *gin.Context) {
-	task, err := T.findTaskByID(c)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving task"})
-		}
+	task, ok := T.findAccessibleTask(c, config.ProjectReadOnly)
+	if !ok {
 		return
 	}
 	c.JSON(http.StatusOK, task.ToSchema())
 }
 
 func (T *TaskHandler) UpdateTask(c *gin.Context) {
-	task, err := T.findTaskByID(c)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving task"})
-		}
+	task, ok := T.findAccessibleTask(c, config.ProjectContributor)
+	if !ok {
 		return
 	}
 
@@ -128,51 +271,146 @@ func (T *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	T.saveTask(c, task, input, true)
+}
+
+func (T *TaskHandler) PatchTask(c *gin.Context) {
+	task, ok := T.findAccessibleTask(c, config.ProjectContributor)
+	if !ok {
+		return
+	}
+
+	var input models.TaskUpdateSchema
+	if !bindPatch(c, taskUpdateDocument(task), &input) {
+		return
+	}
+
+	T.saveTask(c, task, input, false)
+}
+
+func taskUpdateDocument(task *models.Task) models.TaskUpdateSchema {
+	executors := []int{}
+	for _, executor := range task.Executors {
+		executors = append(executors, int(executor.ID))
+	}
+
+	teams := []int{}
+	for _, team := range task.Teams {
+		teams = append(teams, int(team.ID))
+	}
+
+	return models.TaskUpdateSchema{
+		Title:       task.Title,
+		Description: task.Description,
+		Deadline:    task.Deadline.UTC().Format("02.01.2006"),
+		Status:      task.Status,
+		ProjectID:   int(task.ProjectID),
+		Executors:   executors,
+		Teams:       teams,
+	}
+}
+
+func (T *TaskHandler) saveTask(c *gin.Context, task *models.Task, input models.TaskUpdateSchema, replace bool) {
+	before := task.ToSchema()
+
+	if err := validators.ValidateTitle(input.Title); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var ParsedDeadline time.Time
 	if err := utils.ParseDateToTime(input.Deadline, &ParsedDeadline); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect type of deadline"})
 		return
 	}
 
-	if err := validators.ValidateDates(nil, &ParsedDeadline); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	if replace || !ParsedDeadline.Equal(task.Deadline) {
+		if err := validators.ValidateDates(nil, &ParsedDeadline); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 	}
 
-	users, err := T.findUsersByID(input.Executors)
+	users, err := T.findUsersByID(task.OrganizationID, input.Executors)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Couldn't find users"})
 		return
 	}
 
+	previousStatus := task.Status
+
 	task.Title = input.Title
 	task.Description = input.Description
 	task.Deadline = ParsedDeadline
 	if input.Status != "" {
 		task.Status = input.Status
 	}
-	if input.Status != "" {
+	if input.ProjectID != 0 && uint(input.ProjectID) != task.ProjectID {
+		var count int64
+		T.DB.Model(&models.Project{}).Where("id = ? AND organization_id = ?", input.ProjectID, task.OrganizationID).Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+			return
+		}
+		if !T.authorizeProject(c, uint(input.ProjectID), config.ProjectContributor, "Project not found") {
+			return
+		}
 		task.ProjectID = uint(input.ProjectID)
 	}
-	task.Executors = users
 
-	T.DB.Save(&task)
+	if task.Status != previousStatus {
+		if err := workflow.Check(T.DB, task.ProjectID, previousStatus, task.Status); err != nil {
+			respondStatusError(c, err)
+			return
+		}
+	}
+
+	teams, err := T.findTeamsByID(task.OrganizationID, input.Teams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Couldn't find teams"})
+		return
+	}
+
+	actorID, _ := auth.CurrentUserID(c)
+
+	err = T.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Executors", "Teams").Save(task).Error; err != nil {
+			return err
+		}
+		if task.Status != previousStatus {
+			if err := workflow.Record(tx, "task", task.ID, task.ProjectID, previousStatus, task.Status, &actorID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(task).Association("Teams").Replace(teams); err != nil {
+			return err
+		}
+		return tx.Model(task).Association("Executors").Replace(users)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update task"})
+		return
+	}
+
+	audit.RecordChange(c, T.DB, "task.update", "task", task.ID, before, task.ToSchema(), nil)
 
 	c.JSON(http.StatusOK, task.ToSchema())
 }
 
 func (T *TaskHandler) DeleteTask(c *gin.Context) {
-	task, err := T.findTaskByID(c)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving task"})
-		}
+	task, ok := T.findAccessibleTask(c, config.ProjectMaintainer)
+	if !ok {
 		return
 	}
 
-	database.DB.Delete(&task)
+	if err := T.DB.Delete(task).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't delete task"})
+		return
+	}
+
+	audit.RecordChange(c, T.DB, "task.delete", "task", task.ID, task.ToSchema(), nil, nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
 }
 
@@ -188,10 +426,28 @@ func TaskViewSet(c *gin.Context) {
 			taskHandler.ReadTasks(c)
 		}
 	case "POST":
+		if !auth.HasRole(c, config.Admin, config.Manager, config.Member) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "viewers cannot create tasks"})
+			return
+		}
 		taskHandler.CreateTask(c)
 	case "PUT":
+		if !auth.HasRole(c, config.Admin, config.Manager, config.Member) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "viewers cannot update tasks"})
+			return
+		}
 		taskHandler.UpdateTask(c)
+	case "PATCH":
+		if !auth.HasRole(c, config.Admin, config.Manager, config.Member) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "viewers cannot update tasks"})
+			return
+		}
+		taskHandler.PatchTask(c)
 	case "DELETE":
+		if !auth.HasRole(c, config.Admin, config.Manager) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only managers can delete tasks"})
+			return
+		}
 		taskHandler.DeleteTask(c)
 	default:
 		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "method now allowed"})
@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/permissions"
+	"backend/internal/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+func isOrganizationAdmin(c *gin.Context) bool {
+	if auth.HasRole(c, config.Admin) {
+		return true
+	}
+
+	role, _ := auth.CurrentOrganizationRole(c)
+	return role.AtLeast(config.OrganizationAdmin)
+}
+
+func canManageTeam(c *gin.Context, team *models.Team) bool {
+	if isOrganizationAdmin(c) {
+		return true
+	}
+
+	userID, _ := auth.CurrentUserID(c)
+	return team.LeadID != nil && *team.LeadID == userID
+}
+
+func findTeamByID(c *gin.Context, team *models.Team) bool {
+	organizationID, _ := auth.CurrentOrganizationID(c)
+
+	err := database.DB.Preload("Members").
+		Where("organization_id = ?", organizationID).
+		First(team, c.Param("id")).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving team"})
+		}
+		return false
+	}
+	return true
+}
+
+func findOrganizationUsers(organizationID uint, ids []int) ([]models.User, error) {
+	var users []models.User
+
+	err := database.DB.
+		Where("id IN ? AND id IN (?)", ids, permissions.OrganizationMemberIDs(database.DB, organizationID)).
+		Find(&users).Error
+
+	return users, err
+}
+
+func isTeamNameTaken(organizationID uint, name string, excludeID uint) bool {
+	var count int64
+
+	database.DB.Model(&models.Team{}).
+		Where("organization_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", organizationID, name, excludeID).
+		Count(&count)
+
+	return count > 0
+}
+
+func ReadTeams(c *gin.Context) {
+	organizationID, _ := auth.CurrentOrganizationID(c)
+
+	var teams []models.Team
+
+	if err := database.DB.Preload("Members").Where("organization_id = ?", organizationID).Order("name").Find(&teams).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find teams"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TeamsToSchema(teams))
+}
+
+func CreateTeam(c *gin.Context) {
+	if !isOrganizationAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only organization admins can create teams"})
+		return
+	}
+
+	var input models.TeamCreateInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	organizationID, _ := auth.CurrentOrganizationID(c)
+
+	if isTeamNameTaken(organizationID, input.Name, 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "team with this name already exists"})
+		return
+	}
+
+	memberIDs := input.Members
+	if input.LeadID != nil {
+		memberIDs = append(memberIDs, int(*input.LeadID))
+	}
+
+	members, err := findOrganizationUsers(organizationID, memberIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Couldn't find users"})
+		return
+	}
+
+	if input.LeadID != nil {
+		if _, ok := permissions.OrganizationRole(database.DB, organizationID, *input.LeadID); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "team lead must be a member of the organization"})
+			return
+		}
+	}
+
+	team := models.Team{
+		OrganizationID: organizationID,
+		Name:           input.Name,
+		Description:    input.Description,
+		LeadID:         input.LeadID,
+		Members:        members,
+	}
+
+	if err := database.DB.Create(&team).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't create team"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "team.create", "team", team.ID, nil, team.ToSchema(), nil)
+
+	c.JSON(http.StatusCreated, team.ToSchema())
+}
+
+func ReadTeam(c *gin.Context) {
+	var team models.Team
+	if !findTeamByID(c, &team) {
+		return
+	}
+
+	c.JSON(http.StatusOK, team.ToSchema())
+}
+
+func UpdateTeam(c *gin.Context) {
+	var team models.Team
+	if !findTeamByID(c, &team) {
+		return
+	}
+
+	if !canManageTeam(c, &team) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only organization admins and the team lead can update the team"})
+		return
+	}
+
+	var input models.TeamUpdateInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	before := team.ToSchema()
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+			return
+		}
+		if isTeamNameTaken(team.OrganizationID, name, team.ID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "team with this name already exists"})
+			return
+		}
+		team.Name = name
+	}
+
+	if input.Description != nil {
+		team.Description = *input.Description
+	}
+
+	var lead *models.User
+	if input.LeadID != nil {
+		if !isOrganizationAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only organization admins can change the team lead"})
+			return
+		}
+
+		users, err := findOrganizationUsers(team.OrganizationID, []int{int(*input.LeadID)})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Couldn't find users"})
+			return
+		}
+		if len(users) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "team lead must be a member of the organization"})
+			return
+		}
+
+		lead = &users[0]
+		team.LeadID = &lead.ID
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&team).Select("name", "description", "lead_id").Updates(&team).Error; err != nil {
+			return err
+		}
+		if lead != nil && !permissions.IsTeamMember(tx, team.ID, lead.ID) {
+			return tx.Model(&team).Association("Members").Append(lead)
+		}
+		return nil
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update team"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "team.update", "team", team.ID, before, team.ToSchema(), nil)
+
+	c.JSON(http.StatusOK, team.ToSchema())
+}
+
+func DeleteTeam(c *gin.Context) {
+	var team models.Team
+	if !findTeamByID(c, &team) {
+		return
+	}
+
+	if !isOrganizationAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only organization admins can delete teams"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{"team_members", "project_teams", "task_teams"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE team_id = ?", team.ID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&team).Error
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't delete team"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "team.delete", "team", team.ID, team.ToSchema(), nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team deleted successfully"})
+}
+
+func AddTeamMember(c *gin.Context) {
+	var team models.Team
+	if !findTeamByID(c, &team) {
+		return
+	}
+
+	if !canManageTeam(c, &team) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only organization admins and the team lead can manage members"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, c.Param("user_id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving user"})
+		}
+		return
+	}
+
+	if _, ok := permissions.OrganizationRole(database.DB, team.OrganizationID, user.ID); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user is not a member of the team's organization"})
+		return
+	}
+
+	if !permissions.IsTeamMember(database.DB, team.ID, user.ID) {
+		before := team.ToSchema()
+
+		if err := database.DB.Model(&team).Association("Members").Append(&user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't add team member"})
+			return
+		}
+
+		audit.RecordChange(c, database.DB, "team.member.add", "team", team.ID, before, team.ToSchema(), gin.H{"user_id": user.ID})
+	}
+
+	c.JSON(http.StatusOK, team.ToSchema())
+}
+
+func RemoveTeamMember(c *gin.Context) {
+	var team models.Team
+	if !findTeamByID(c, &team) {
+		return
+	}
+
+	if !canManageTeam(c, &team) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only organization admins and the team lead can manage members"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, c.Param("user_id")).Error; err != nil || !permissions.IsTeamMember(database.DB, team.ID, user.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team member not found"})
+		return
+	}
+
+	before := team.ToSchema()
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if team.LeadID != nil && *team.LeadID == user.ID {
+			if err := tx.Model(&team).Update("lead_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&team).Association("Members").Delete(&user)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't remove team member"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "team.member.remove", "team", team.ID, before, team.ToSchema(), gin.H{"user_id": user.ID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team member removed successfully"})
+}
+
+func (u *UserHandler) ReadProjectTeams(c *gin.Context) {
+	project, ok := u.findAccessibleProject(c, config.ProjectReadOnly)
+	if !ok {
+		return
+	}
+
+	var assignments []models.ProjectTeam
+	if err := u.DB.Where("project_id = ?", project.ID).Find(&assignments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find project teams"})
+		return
+	}
+
+	roles := make(map[uint]config.ProjectRoleChoice, len(assignments))
+	for _, assignment := range assignments {
+		roles[assignment.TeamID] = assignment.Role
+	}
+
+	var teams []models.Team
+	if err := u.DB.Preload("Members").Where("id IN (?)", u.DB.Model(&models.ProjectTeam{}).Select("team_id").Where("project_id = ?", project.ID)).Order("name").Find(&teams).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find project teams"})
+		return
+	}
+
+	projectTeams := []models.ProjectTeamSchema{}
+	for _, team := range teams {
+		projectTeams = append(projectTeams, models.ProjectTeamSchema{Team: team.ToSchema(), Role: roles[team.ID]})
+	}
+
+	c.JSON(http.StatusOK, projectTeams)
+}
+
+func (u *UserHandler) SetProjectTeam(c *gin.Context) {
+	project, ok := u.findAccessibleProject(c, config.ProjectMaintainer)
+	if !ok {
+		return
+	}
+
+	var input models.ProjectTeamInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !input.Role.IsValid() || input.Role == config.ProjectOwner {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "teams can be granted the maintainer, contributor or read_only role"})
+		return
+	}
+
+	var team models.Team
+	if err := u.DB.Preload("Members").Where("organization_id = ?", project.OrganizationID).First(&team, c.Param("team_id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving team"})
+		}
+		return
+	}
+
+	var current models.ProjectTeam
+	u.DB.Where("project_id = ? AND team_id = ?", project.ID, team.ID).Limit(1).Find(&current)
+
+	if !u.canGrantProjectRole(c, project.ID, input.Role) || (current.Role != "" && !u.canGrantProjectRole(c, project.ID, current.Role)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient project role"})
+		return
+	}
+
+	if err := permissions.SetProjectTeamRole(u.DB, project.ID, team.ID, input.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update project team"})
+		return
+	}
+
+	audit.RecordChange(c, u.DB, "project.team.set", "project", project.ID,
+		gin.H{"role": current.Role}, gin.H{"role": input.Role}, gin.H{"team_id": team.ID})
+
+	c.JSON(http.StatusOK, models.ProjectTeamSchema{Team: team.ToSchema(), Role: input.Role})
+}
+
+func (u *UserHandler) RemoveProjectTeam(c *gin.Context) {
+	project, ok := u.findAccessibleProject(c, config.ProjectMaintainer)
+	if !ok {
+		return
+	}
+
+	var assignment models.ProjectTeam
+	if err := u.DB.Where("project_id = ? AND team_id = ?", project.ID, c.Param("team_id")).First(&assignment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project team not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving project team"})
+		}
+		return
+	}
+
+	if !u.canGrantProjectRole(c, project.ID, assignment.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient project role"})
+		return
+	}
+
+	if err := u.DB.Where("project_id = ? AND team_id = ?", project.ID, assignment.TeamID).Delete(&models.ProjectTeam{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't remove project team"})
+		return
+	}
+
+	audit.RecordChange(c, u.DB, "project.team.remove", "project", project.ID,
+		gin.H{"role": assignment.Role}, nil, gin.H{"team_id": assignment.TeamID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project team removed successfully"})
+}
+
+func ProjectTeamsViewSet(c *gin.Context) {
+	userHandler := UserHandler{DB: database.DB}
+	switch c.Request.Method {
+	case "GET":
+		userHandler.ReadProjectTeams(c)
+	case "PUT":
+		userHandler.SetProjectTeam(c)
+	case "DELETE":
+		userHandler.RemoveProjectTeam(c)
+	default:
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "method now allowed"})
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func ReadPersonalTokens(c *gin.Context) {
+	userID, _ := auth.CurrentUserID(c)
+
+	var personalTokens []models.PersonalAccessToken
+
+	if err := database.DB.Where("user_id = ? AND revoked_at IS NULL", userID).Order("id").Find(&personalTokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find tokens"})
+		return
+	}
+
+	serializedTokens := []models.PersonalAccessTokenSchema{}
+	for _, personalToken := range personalTokens {
+		serializedTokens = append(serializedTokens, personalToken.ToSchema())
+	}
+
+	c.JSON(http.StatusOK, serializedTokens)
+}
+
+func CreatePersonalToken(c *gin.Context) {
+	var input models.PersonalAccessTokenCreateInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	if input.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	if len(input.Scopes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one scope is required"})
+		return
+	}
+
+	scopes := make([]string, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		if !scope.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown scope " + string(scope)})
+			return
+		}
+		scopes = append(scopes, string(scope))
+	}
+
+	var expiresAt *time.Time
+	if input.ExpiresAt != "" {
+		var parsedExpiresAt time.Time
+		if err := utils.ParseDateToTime(input.ExpiresAt, &parsedExpiresAt); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect type of expires_at"})
+			return
+		}
+		if parsedExpiresAt.Before(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+			return
+		}
+		expiresAt = &parsedExpiresAt
+	}
+
+	var rawToken, prefix string
+	if err := auth.GeneratePersonalToken(&rawToken, &prefix); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate token"})
+		return
+	}
+
+	userID, _ := auth.CurrentUserID(c)
+
+	organizationID, _ := auth.CurrentOrganizationID(c)
+
+	personalToken := models.PersonalAccessToken{
+		UserID:         userID,
+		OrganizationID: organizationID,
+		Name:           input.Name,
+		Prefix:         prefix,
+		TokenHash:      utils.HashToken(rawToken),
+		Scopes:         strings.Join(scopes, ","),
+		ExpiresAt:      expiresAt,
+	}
+
+	if err := database.DB.Create(&personalToken).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't create token"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "personal_token.create", "personal_access_token", personalToken.ID, nil, personalToken.ToSchema(), nil)
+
+	schema := personalToken.ToSchema()
+	schema.Token = rawToken
+
+	c.JSON(http.StatusCreated, schema)
+}
+
+func RevokePersonalToken(c *gin.Context) {
+	userID, _ := auth.CurrentUserID(c)
+
+	var personalToken models.PersonalAccessToken
+
+	if err := database.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", c.Param("id"), userID).First(&personalToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving token"})
+		}
+		return
+	}
+
+	if err := database.DB.Model(&personalToken).Update("revoked_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't revoke token"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "personal_token.revoke", "personal_access_token", personalToken.ID, personalToken.ToSchema(), nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/permissions"
+	"backend/internal/trash"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+func findTrashedProject(c *gin.Context) (*models.Project, bool) {
+	var project models.Project
+	organizationID, _ := auth.CurrentOrganizationID(c)
+
+	err := database.DB.Unscoped().Preload("Executors").Preload("Teams").
+		Where("organization_id = ? AND deleted_at IS NOT NULL", organizationID).
+		First(&project, c.Param("id")).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found in trash"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving project"})
+		}
+		return nil, false
+	}
+
+	taskHandler := TaskHandler{DB: database.DB}
+	if !taskHandler.authorizeProject(c, project.ID, config.ProjectOwner, "Project not found in trash") {
+		return nil, false
+	}
+
+	return &project, true
+}
+
+func findTrashedTask(c *gin.Context) (*models.Task, bool) {
+	var task models.Task
+	organizationID, _ := auth.CurrentOrganizationID(c)
+
+	err := database.DB.Unscoped().Preload("Executors").Preload("Teams").
+		Where("organization_id = ? AND deleted_at IS NOT NULL", organizationID).
+		First(&task, c.Param("id")).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found in trash"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving task"})
+		}
+		return nil, false
+	}
+
+	taskHandler := TaskHandler{DB: database.DB}
+	if !taskHandler.authorizeTask(c, &task, config.ProjectMaintainer) {
+		return nil, false
+	}
+
+	return &task, true
+}
+
+func ReadTrash(c *gin.Context) {
+	entityType := c.Query("type")
+	if entityType != "" && entityType != "project" && entityType != "task" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect type"})
+		return
+	}
+
+	organizationID, _ := auth.CurrentOrganizationID(c)
+	userID, _ := auth.CurrentUserID(c)
+	isAdmin := auth.HasRole(c, config.Admin)
+	retention := trash.Retention()
+
+	response := models.TrashSchema{
+		Projects: []models.TrashedProjectSchema{},
+		Tasks:    []models.TrashedTaskSchema{},
+	}
+
+	if entityType != "task" {
+		query := database.DB.Unscoped().Where("organization_id = ? AND deleted_at IS NOT NULL", organizationID)
+		if !isAdmin {
+			query = query.Where("id IN (?)", permissions.MemberProjectIDs(database.DB, userID))
+		}
+
+		var projects []models.Project
+		if err := query.Preload("Executors").Preload("Teams").Order("deleted_at DESC, id DESC").Find(&projects).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find deleted projects"})
+			return
+		}
+
+		for _, project := range projects {
+			response.Projects = append(response.Projects, models.TrashedProjectSchema{
+				ProjectSchema: project.ToSchema(),
+				DeletedAt:     project.DeletedAt.Time,
+				PurgeAt:       project.DeletedAt.Time.Add(retention),
+			})
+		}
+	}
+
+	if entityType != "project" {
+		query := database.DB.Unscoped().Where("organization_id = ? AND deleted_at IS NOT NULL", organizationID)
+		if !isAdmin {
+			query = query.Where("project_id IN (?) OR id IN (?)", permissions.MemberProjectIDs(database.DB, userID), permissions.TeamTaskIDs(database.DB, userID))
+		}
+
+		var tasks []models.Task
+		if err := query.Preload("Executors").Preload("Teams").Order("deleted_at DESC, id DESC").Find(&tasks).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find deleted tasks"})
+			return
+		}
+
+		for _, task := range tasks {
+			response.Tasks = append(response.Tasks, models.TrashedTaskSchema{
+				TaskSchema: task.ToSchema(),
+				DeletedAt:  task.DeletedAt.Time,
+				PurgeAt:    task.DeletedAt.Time.Add(retention),
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func RestoreProject(c *gin.Context) {
+	if !auth.HasRole(c, config.Admin, config.Manager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers can restore projects"})
+		return
+	}
+
+	project, ok := findTrashedProject(c)
+	if !ok {
+		return
+	}
+
+	var restoredTasks int64
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		restoredTasks, err = trash.RestoreProject(tx, project)
+		return err
+	})
+
+	if errors.Is(err, trash.ErrTitleConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't restore project"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "project.restore", "project", project.ID, nil, project.ToSchema(), map[string]interface{}{"restored_tasks": restoredTasks})
+
+	c.JSON(http.StatusOK, project.ToSchema())
+}
+
+func RestoreTask(c *gin.Context) {
+	if !auth.HasRole(c, config.Admin, config.Manager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers can restore tasks"})
+		return
+	}
+
+	task, ok := findTrashedTask(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.First(&models.Project{}, task.ProjectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusConflict, gin.H{"error": "the task's project is deleted, restore the project first"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving project"})
+		}
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return trash.RestoreTask(tx, task)
+	})
+
+	if errors.Is(err, trash.ErrTitleConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't restore task"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "task.restore", "task", task.ID, nil, task.ToSchema(), nil)
+
+	c.JSON(http.StatusOK, task.ToSchema())
+}
+
+func PurgeProject(c *gin.Context) {
+	if !auth.HasRole(c, config.Admin, config.Manager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers can delete projects"})
+		return
+	}
+
+	project, ok := findTrashedProject(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return trash.PurgeProjects(tx, []uint{project.ID})
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't permanently delete project"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "project.purge", "project", project.ID, project.ToSchema(), nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project permanently deleted"})
+}
+
+func PurgeTask(c *gin.Context) {
+	if !auth.HasRole(c, config.Admin, config.Manager) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only managers can delete tasks"})
+		return
+	}
+
+	task, ok := findTrashedTask(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return trash.PurgeTasks(tx, []uint{task.ID})
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't permanently delete task"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "task.purge", "task", task.ID, task.ToSchema(), nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task permanently deleted"})
+}
@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"backend/internal/audit"
 	"backend/internal/auth"
+	"backend/internal/config"
 	"backend/internal/database"
+	"backend/internal/loginguard"
 	"backend/internal/models"
+	"backend/internal/password"
+	"backend/internal/permissions"
 	"backend/internal/utils"
 	"backend/internal/validators"
+	"errors"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"log"
 	"net/http"
 	"time"
 )
@@ -34,7 +41,11 @@ func RegisterUser(c *gin.Context) {
 		}
 	}
 
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	hashedPassword, err := password.Hash(input.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't hash password"})
+		return
+	}
 
 	var parsedDate time.Time
 
@@ -48,11 +59,29 @@ func RegisterUser(c *gin.Context) {
 		LastName:  input.LastName,
 		BirthDate: parsedDate,
 		Gender:    input.Gender,
+		Role:      config.Member,
 		Email:     input.Email,
-		Password:  string(hashedPassword),
+		Password:  hashedPassword,
+		IsActive:  true,
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return createPersonalOrganization(tx, &user)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't register user"})
+		return
 	}
 
-	database.DB.Create(&user)
+	audit.RecordChange(c, database.DB, "user.register", "user", user.ID, nil, user.ToSchema(), nil)
+
+	if err := sendVerificationEmail(database.DB, &user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
 
 	c.JSON(http.StatusCreated, user.ToSchema())
 }
@@ -65,21 +94,120 @@ func LoginUser(c *gin.Context) {
 		return
 	}
 
+	if throttleLogin(c, input.Email) {
+		return
+	}
+
 	var user models.User
 
 	if err := validators.ValidateUserLogin(database.DB, &user, input.Email, input.Password); err != nil {
+		recordLoginFailure(c, input.Email)
 		utils.RaiseBadRequestError(c, err)
 		return
 	}
 
-	var token string
+	if user.EmailVerifiedAt == nil && config.UnverifiedEmailPolicy() == config.BlockUnverifiedLogin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "email address is not verified"})
+		return
+	}
+
+	if startMFALogin(c, &user) {
+		return
+	}
+
+	if err := loginguard.Reset(database.DB, input.Email); err != nil {
+		log.Printf("Failed to reset login failures for user %d: %v", user.ID, err)
+	}
+
+	issueTokens(c, &user)
+}
+
+func issueTokens(c *gin.Context, user *models.User) {
+	organizationID := permissions.DefaultOrganizationID(database.DB, user.ID)
+
+	familyID, err := auth.StartSession(database.DB, user.ID, organizationID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate token"})
+		return
+	}
 
-	if err := auth.GenerateToken(&token, user.ID); err != nil {
+	var refreshToken string
+
+	if err := auth.IssueRefreshToken(database.DB, &refreshToken, user.ID, familyID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate token"})
+		return
+	}
+
+	var accessToken string
+
+	if err := auth.GenerateToken(&accessToken, user, familyID, organizationID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access": accessToken, "refresh": refreshToken})
+}
+
+func RefreshToken(c *gin.Context) {
+	var input models.RefreshInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
 		utils.RaiseBadRequestError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"access": token})
+	var refreshToken string
+	var rotated models.RefreshToken
+
+	if err := auth.RotateRefreshToken(database.DB, input.Refresh, &refreshToken, &rotated); err != nil {
+		if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't refresh token"})
+		}
+		return
+	}
+
+	var user models.User
+
+	if err := database.DB.First(&user, rotated.UserID).Error; err != nil || !user.IsActive {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+		return
+	}
+
+	var accessToken string
+
+	organizationID := auth.SessionOrganizationID(database.DB, rotated.FamilyID)
+
+	if err := auth.GenerateToken(&accessToken, &user, rotated.FamilyID, organizationID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access": accessToken, "refresh": refreshToken})
+}
+
+func Logout(c *gin.Context) {
+	claims, ok := c.Value("claims").(*auth.Claims)
+
+	if !ok {
+		c.JSON(http.StatusUnauthorized, "token claims are missing in context")
+		return
+	}
+
+	if err := auth.RevokeAccessToken(database.DB, claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't revoke token"})
+		return
+	}
+
+	if claims.FamilyID != "" {
+		if err := auth.RevokeFamily(database.DB, claims.FamilyID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't revoke refresh token"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
 func Profile(c *gin.Context) {
@@ -102,3 +230,62 @@ func Profile(c *gin.Context) {
 
 	c.JSON(http.StatusOK, user.ToSchema())
 }
+
+func AssignRole(c *gin.Context) {
+	var input models.RoleInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	if !input.Role.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown role"})
+		return
+	}
+
+	var user models.User
+
+	if err := database.DB.First(&user, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving user"})
+		}
+		return
+	}
+
+	if user.Role == input.Role {
+		c.JSON(http.StatusOK, user.ToSchema())
+		return
+	}
+
+	before := user.ToSchema()
+	user.Role = input.Role
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if input.Role != config.Admin {
+			if err := checkNotLastActiveAdmin(tx, user.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&user).Update("role", input.Role).Error; err != nil {
+			return err
+		}
+		return auth.RevokeUserRefreshTokens(tx, user.ID)
+	})
+
+	if errors.Is(err, errLastActiveAdmin) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot demote the last admin"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update role"})
+		return
+	}
+
+	audit.RecordChange(c, database.DB, "user.role.update", "user", user.ID, before, user.ToSchema(), nil)
+
+	c.JSON(http.StatusOK, user.ToSchema())
+}
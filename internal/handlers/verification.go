@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/mailer"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+func sendVerificationEmail(db *gorm.DB, user *models.User) error {
+	var token string
+
+	claims := auth.PurposeClaims{UserID: user.ID, Email: user.Email, Purpose: auth.EmailVerificationPurpose}
+
+	if err := auth.GeneratePurposeToken(&token, claims, config.EmailVerificationTokenLifetime); err != nil {
+		return err
+	}
+
+	sentAt := time.Now()
+	if err := db.Model(user).Update("verification_sent_at", sentAt).Error; err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/users/verify?token=%s", os.Getenv("APP_URL"), token)
+
+	return mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf(
+			"Hello, %s!\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s\n",
+			user.FirstName, config.EmailVerificationTokenLifetime, link,
+		),
+	})
+}
+
+func VerifyEmail(c *gin.Context) {
+	var claims auth.PurposeClaims
+
+	if err := auth.ParsePurposeToken(c.Query("token"), auth.EmailVerificationPurpose, &claims); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+
+	if err := database.DB.First(&user, claims.UserID).Error; err != nil || user.Email != claims.Email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	if user.EmailVerifiedAt == nil {
+		before := user.ToSchema()
+
+		if err := database.DB.Model(&user).Update("email_verified_at", time.Now()).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't verify email"})
+			return
+		}
+
+		audit.RecordChange(c, database.DB, "user.email.verify", "user", user.ID, before, user.ToSchema(), nil)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email address verified successfully"})
+}
+
+func ResendVerification(c *gin.Context) {
+	var input models.ResendVerificationInput
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	var user models.User
+
+	if err := database.DB.Where("email = ?", input.Email).First(&user).Error; err == nil && user.EmailVerifiedAt == nil {
+		recentlySent := user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < config.VerificationResendInterval
+
+		if !recentlySent {
+			if err := sendVerificationEmail(database.DB, &user); err != nil {
+				log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists and is not verified, a verification link has been sent"})
+}
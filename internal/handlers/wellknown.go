@@ -0,0 +1,11 @@
+package handlers
+
+import (
+	"backend/internal/auth"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"keys": auth.PublicJWKs()})
+}
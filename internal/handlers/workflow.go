@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"backend/internal/audit"
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"backend/internal/workflow"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+func respondStatusError(c *gin.Context, err error) {
+	if errors.Is(err, workflow.ErrUnknownStatus) || errors.Is(err, workflow.ErrTransitionNotAllowed) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't check status transition"})
+	}
+}
+
+func readStatusHistory(c *gin.Context, entityType string, entityID uint) {
+	var transitions []models.StatusTransition
+
+	if err := database.DB.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Order("id").Find(&transitions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find status history"})
+		return
+	}
+
+	serializedTransitions := []models.StatusTransitionSchema{}
+	for _, transition := range transitions {
+		serializedTransitions = append(serializedTransitions, transition.ToSchema())
+	}
+
+	c.JSON(http.StatusOK, serializedTransitions)
+}
+
+func ProjectStatusAction(action workflow.Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.HasRole(c, config.Admin, config.Manager, config.Member) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "viewers cannot update projects"})
+			return
+		}
+
+		userHandler := UserHandler{DB: database.DB}
+
+		project, ok := userHandler.findAccessibleProject(c, config.ProjectMaintainer)
+		if !ok {
+			return
+		}
+
+		if !action.Allows(project.Status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cannot " + action.Name + " a project with status " + string(project.Status)})
+			return
+		}
+
+		if err := workflow.Check(database.DB, project.ID, project.Status, action.To); err != nil {
+			respondStatusError(c, err)
+			return
+		}
+
+		before := project.ToSchema()
+		previousStatus := project.Status
+		actorID, _ := auth.CurrentUserID(c)
+
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(project).Update("status", action.To).Error; err != nil {
+				return err
+			}
+			return workflow.Record(tx, "project", project.ID, project.ID, previousStatus, action.To, &actorID)
+		})
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update project status"})
+			return
+		}
+
+		audit.RecordChange(c, database.DB, "project."+action.Name, "project", project.ID, before, project.ToSchema(), nil)
+
+		c.JSON(http.StatusOK, project.ToSchema())
+	}
+}
+
+func TaskStatusAction(action workflow.Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.HasRole(c, config.Admin, config.Manager, config.Member) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "viewers cannot update tasks"})
+			return
+		}
+
+		taskHandler := TaskHandler{DB: database.DB}
+
+		task, ok := taskHandler.findAccessibleTask(c, config.ProjectContributor)
+		if !ok {
+			return
+		}
+
+		if !action.Allows(task.Status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cannot " + action.Name + " a task with status " + string(task.Status)})
+			return
+		}
+
+		if err := workflow.Check(database.DB, task.ProjectID, task.Status, action.To); err != nil {
+			respondStatusError(c, err)
+			return
+		}
+
+		before := task.ToSchema()
+		previousStatus := task.Status
+		actorID, _ := auth.CurrentUserID(c)
+
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(task).Update("status", action.To).Error; err != nil {
+				return err
+			}
+			return workflow.Record(tx, "task", task.ID, task.ProjectID, previousStatus, action.To, &actorID)
+		})
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update task status"})
+			return
+		}
+
+		audit.RecordChange(c, database.DB, "task."+action.Name, "task", task.ID, before, task.ToSchema(), nil)
+
+		c.JSON(http.StatusOK, task.ToSchema())
+	}
+}
+
+func ReadProjectTransitions(c *gin.Context) {
+	userHandler := UserHandler{DB: database.DB}
+
+	project, ok := userHandler.findAccessibleProject(c, config.ProjectReadOnly)
+	if !ok {
+		return
+	}
+
+	transitions, custom, err := workflow.Transitions(database.DB, project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find project transitions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transitionsToSchema(transitions, custom))
+}
+
+func SetProjectTransitions(c *gin.Context) {
+	userHandler := UserHandler{DB: database.DB}
+
+	project, ok := userHandler.findAccessibleProject(c, config.ProjectOwner)
+	if !ok {
+		return
+	}
+
+	var input models.ProjectTransitionsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.RaiseBadRequestError(c, err)
+		return
+	}
+
+	rules := make([]models.ProjectTransition, 0, len(input.Transitions))
+	for _, transition := range input.Transitions {
+		rules = append(rules, models.ProjectTransition{FromStatus: transition.From, ToStatus: transition.To})
+	}
+
+	if err := workflow.ValidateTransitions(rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous, previousCustom, err := workflow.Transitions(database.DB, project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find project transitions"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		return workflow.SetTransitions(tx, project.ID, rules)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't update project transitions"})
+		return
+	}
+
+	transitions, custom, err := workflow.Transitions(database.DB, project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "couldn't find project transitions"})
+		return
+	}
+
+	schema := transitionsToSchema(transitions, custom)
+
+	audit.RecordChange(c, database.DB, "project.transitions.update", "project", project.ID,
+		transitionsToSchema(previous, previousCustom), schema, nil)
+
+	c.JSON(http.StatusOK, schema)
+}
+
+func transitionsToSchema(transitions []models.ProjectTransition, custom bool) models.ProjectTransitionsSchema {
+	rules := []models.TransitionRuleSchema{}
+	for _, transition := range transitions {
+		rules = append(rules, models.TransitionRuleSchema{From: transition.FromStatus, To: transition.ToStatus})
+	}
+
+	return models.ProjectTransitionsSchema{Custom: custom, Transitions: rules}
+}
+
+func ReadProjectStatusHistory(c *gin.Context) {
+	userHandler := UserHandler{DB: database.DB}
+
+	project, ok := userHandler.findAccessibleProject(c, config.ProjectReadOnly)
+	if !ok {
+		return
+	}
+
+	readStatusHistory(c, "project", project.ID)
+}
+
+func ReadTaskStatusHistory(c *gin.Context) {
+	taskHandler := TaskHandler{DB: database.DB}
+
+	task, ok := taskHandler.findAccessibleTask(c, config.ProjectReadOnly)
+	if !ok {
+		return
+	}
+
+	readStatusHistory(c, "task", task.ID)
+}
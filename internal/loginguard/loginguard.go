@@ -0,0 +1,139 @@
+package loginguard
+
+import (
+	"backend/internal/config"
+	"backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	backoffStart = 3
+	maxBackoff   = time.Minute
+)
+
+func accountSubject(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
+
+func lockoutDuration() time.Duration {
+	return config.GetEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+}
+
+func failureWindow() time.Duration {
+	return config.GetEnvDuration("LOGIN_FAILURE_WINDOW", time.Hour)
+}
+
+func thresholdFor(subject string) int {
+	if strings.HasPrefix(subject, "ip:") {
+		return config.GetEnvInt("LOGIN_IP_LOCKOUT_THRESHOLD", 50)
+	}
+	return config.GetEnvInt("LOGIN_LOCKOUT_THRESHOLD", 10)
+}
+
+func backoff(failures int) time.Duration {
+	if failures < backoffStart {
+		return 0
+	}
+
+	delay := time.Duration(math.Pow(2, float64(failures-backoffStart))) * time.Second
+	if delay > maxBackoff || delay <= 0 {
+		return maxBackoff
+	}
+
+	return delay
+}
+
+func RetryAfter(db *gorm.DB, email, ip string) time.Duration {
+	var failures []models.LoginFailure
+
+	if err := db.Where("subject IN ?", []string{accountSubject(email), ipSubject(ip)}).Find(&failures).Error; err != nil {
+		return 0
+	}
+
+	now := time.Now()
+	var wait time.Duration
+
+	for _, failure := range failures {
+		if failure.LockedUntil != nil && failure.LockedUntil.After(now) {
+			wait = max(wait, failure.LockedUntil.Sub(now))
+			continue
+		}
+
+		if now.Sub(failure.LastFailedAt) > failureWindow() {
+			continue
+		}
+
+		if next := failure.LastFailedAt.Add(backoff(failure.Failures)); next.After(now) {
+			wait = max(wait, next.Sub(now))
+		}
+	}
+
+	return wait
+}
+
+func RecordFailure(db *gorm.DB, email, ip string) ([]string, error) {
+	var locked []string
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, subject := range []string{accountSubject(email), ipSubject(ip)} {
+			lockedNow, err := recordSubjectFailure(tx, subject)
+			if err != nil {
+				return err
+			}
+			if lockedNow {
+				locked = append(locked, subject)
+			}
+		}
+		return nil
+	})
+
+	return locked, err
+}
+
+func recordSubjectFailure(tx *gorm.DB, subject string) (bool, error) {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.LoginFailure{Subject: subject}).Error; err != nil {
+		return false, err
+	}
+
+	var failure models.LoginFailure
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("subject = ?", subject).First(&failure).Error; err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+
+	if now.Sub(failure.LastFailedAt) > failureWindow() {
+		failure.Failures = 0
+	}
+
+	failure.Failures++
+	failure.LastFailedAt = now
+
+	locked := false
+
+	if failure.Failures >= thresholdFor(subject) && (failure.LockedUntil == nil || now.After(*failure.LockedUntil)) {
+		lockedUntil := now.Add(lockoutDuration())
+		failure.LockedUntil = &lockedUntil
+		failure.Failures = 0
+		locked = true
+	}
+
+	return locked, tx.Save(&failure).Error
+}
+
+func Reset(db *gorm.DB, email string) error {
+	return db.Unscoped().Where("subject = ?", accountSubject(email)).Delete(&models.LoginFailure{}).Error
+}
+
+func IsAccountSubject(subject string) bool {
+	return strings.HasPrefix(subject, "email:")
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLog struct {
+	ID             uint `gorm:"primarykey"`
+	CreatedAt      time.Time
+	ActorID        *uint  `gorm:"index"`
+	ImpersonatorID *uint  `gorm:"index"`
+	OrganizationID *uint  `gorm:"index"`
+	Action         string `gorm:"index"`
+	EntityType     string `gorm:"index"`
+	EntityID       uint   `gorm:"index"`
+	IP             string
+	RequestID      string `gorm:"index"`
+	Changes        string `gorm:"type:jsonb;default:'{}'"`
+	Details        string `gorm:"type:jsonb;default:'{}'"`
+}
+
+func (a *AuditLog) ToSchema() AuditLogSchema {
+	return AuditLogSchema{
+		ID:             a.ID,
+		CreatedAt:      a.CreatedAt,
+		ActorID:        a.ActorID,
+		ImpersonatorID: a.ImpersonatorID,
+		OrganizationID: a.OrganizationID,
+		Action:         a.Action,
+		EntityType:     a.EntityType,
+		EntityID:       a.EntityID,
+		IP:             a.IP,
+		RequestID:      a.RequestID,
+		Changes:        rawJSON(a.Changes),
+		Details:        rawJSON(a.Details),
+	}
+}
+
+func rawJSON(value string) json.RawMessage {
+	if value == "" {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(value)
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"backend/internal/config"
+
+	"gorm.io/gorm"
+)
+
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint   `gorm:"index"`
+	FamilyID  string `gorm:"index"`
+	TokenHash string `gorm:"unique"`
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+type RevokedToken struct {
+	gorm.Model
+	JTI       string `gorm:"unique"`
+	ExpiresAt time.Time
+}
+
+type PasswordResetToken struct {
+	gorm.Model
+	UserID    uint   `gorm:"index"`
+	TokenHash string `gorm:"unique"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+type PersonalAccessToken struct {
+	gorm.Model
+	UserID         uint `gorm:"index"`
+	OrganizationID uint
+	Name           string
+	Prefix         string
+	TokenHash      string `gorm:"unique"`
+	Scopes         string
+	ExpiresAt      *time.Time
+	LastUsedAt     *time.Time
+	RevokedAt      *time.Time
+}
+
+func (t *PersonalAccessToken) ScopeList() []config.ScopeChoice {
+	scopes := []config.ScopeChoice{}
+
+	for _, scope := range strings.Split(t.Scopes, ",") {
+		if scope != "" {
+			scopes = append(scopes, config.ScopeChoice(scope))
+		}
+	}
+
+	return scopes
+}
+
+func (t *PersonalAccessToken) ToSchema() PersonalAccessTokenSchema {
+	return PersonalAccessTokenSchema{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     t.ScopeList(),
+		CreatedAt:  t.CreatedAt,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+	}
+}
+
+type RecoveryCode struct {
+	gorm.Model
+	UserID   uint `gorm:"index"`
+	CodeHash string
+	UsedAt   *time.Time
+}
+
+type LoginFailure struct {
+	gorm.Model
+	Subject      string `gorm:"unique"`
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
+
+type OIDCLoginState struct {
+	gorm.Model
+	State        string `gorm:"unique"`
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+}
+
+type Session struct {
+	ID             string `gorm:"primaryKey"`
+	UserID         uint   `gorm:"index"`
+	OrganizationID uint
+	UserAgent      string
+	IP             string
+	CreatedAt      time.Time
+	LastSeenAt     time.Time
+	RevokedAt      *time.Time
+}
+
+func (s *Session) ToSchema(currentID string) SessionSchema {
+	return SessionSchema{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+		Current:    s.ID == currentID,
+	}
+}
+
+type PasswordHistory struct {
+	ID           uint `gorm:"primarykey"`
+	UserID       uint `gorm:"index"`
+	PasswordHash string
+	CreatedAt    time.Time
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"backend/internal/config"
+
+	"gorm.io/gorm"
+)
+
+type ProjectInvitation struct {
+	gorm.Model
+	ProjectID    uint `gorm:"index"`
+	Email        string
+	Role         config.ProjectRoleChoice
+	TokenHash    string `gorm:"unique"`
+	InvitedByID  uint
+	SentAt       time.Time
+	ExpiresAt    time.Time
+	AcceptedAt   *time.Time
+	AcceptedByID *uint
+	DeclinedAt   *time.Time
+	RevokedAt    *time.Time
+}
+
+func (i *ProjectInvitation) Status() string {
+	switch {
+	case i.AcceptedAt != nil:
+		return "accepted"
+	case i.DeclinedAt != nil:
+		return "declined"
+	case i.RevokedAt != nil:
+		return "revoked"
+	case time.Now().After(i.ExpiresAt):
+		return "expired"
+	}
+	return "pending"
+}
+
+func (i *ProjectInvitation) IsPending() bool {
+	return i.Status() == "pending"
+}
+
+func (i *ProjectInvitation) ToSchema() ProjectInvitationSchema {
+	return ProjectInvitationSchema{
+		ID:          i.ID,
+		ProjectID:   i.ProjectID,
+		Email:       i.Email,
+		Role:        i.Role,
+		Status:      i.Status(),
+		InvitedByID: i.InvitedByID,
+		SentAt:      i.SentAt,
+		ExpiresAt:   i.ExpiresAt,
+	}
+}
@@ -13,9 +13,22 @@ type User struct {
 	LastName  string
 	BirthDate time.Time
 	Gender    config.GenderChoice
-	Role      string
-	Email     string `gorm:"unique"`
+	Role      config.RoleChoice `gorm:"default:member"`
+	Email     string            `gorm:"unique"`
 	Password  string
+
+	IsActive              bool `gorm:"default:true"`
+	PasswordResetRequired bool
+
+	EmailVerifiedAt    *time.Time
+	VerificationSentAt *time.Time
+	EmailChangeNonce   string
+
+	TOTPSecret   string
+	TOTPEnabled  bool
+	TOTPLastStep int64
+
+	OIDCSubject *string `gorm:"unique"`
 }
 
 func (u *User) ToSchema() UserSchema {
@@ -25,29 +38,38 @@ func (u *User) ToSchema() UserSchema {
 		LastName:  u.LastName,
 		BirthDate: u.BirthDate.Format("02.01.2006"),
 		Gender:    u.Gender,
+		Role:      u.Role,
 		Email:     u.Email,
+
+		EmailVerified: u.EmailVerifiedAt != nil,
+		TOTPEnabled:   u.TOTPEnabled,
+		IsActive:      u.IsActive,
 	}
 }
 
 type Task struct {
 	gorm.Model
-	Title       string `gorm:"unique"`
-	Description string
-	Deadline    time.Time
-	Status      config.StatusChoice `gorm:"default:created"`
-	ProjectID   uint
-	Executors   []User `gorm:"many2many:task_users"`
+	OrganizationID uint   `gorm:"uniqueIndex:idx_tasks_organization_active_title,where:deleted_at IS NULL"`
+	Title          string `gorm:"uniqueIndex:idx_tasks_organization_active_title,where:deleted_at IS NULL"`
+	Description    string
+	Deadline       time.Time
+	Status         config.StatusChoice `gorm:"default:created"`
+	ProjectID      uint
+	Executors      []User `gorm:"many2many:task_users"`
+	Teams          []Team `gorm:"many2many:task_teams"`
 }
 
 func (t *Task) ToSchema() TaskSchema {
 	return TaskSchema{
-		ID:          t.ID,
-		Title:       t.Title,
-		Description: t.Description,
-		Deadline:    t.Deadline.Format("01.06.2006"),
-		Status:      t.Status,
-		ProjectID:   t.ProjectID,
-		Executors:   t.UsersToSchema(t.Executors),
+		ID:             t.ID,
+		OrganizationID: t.OrganizationID,
+		Title:          t.Title,
+		Description:    t.Description,
+		Deadline:       t.Deadline.Format("01.06.2006"),
+		Status:         t.Status,
+		ProjectID:      t.ProjectID,
+		Executors:      t.UsersToSchema(t.Executors),
+		Teams:          TeamsToSchema(t.Teams),
 	}
 }
 
@@ -63,13 +85,15 @@ func (t *Task) UsersToSchema(users []User) []UserSchema {
 
 type Project struct {
 	gorm.Model
-	Title       string `gorm:"unique"`
-	Description string
-	StartedAt   time.Time
-	Deadline    time.Time
-	Status      config.StatusChoice `gorm:"default:created"`
-	Executors   []User              `gorm:"many2many:project_users"`
-	Tasks       []Task              `gorm:"many2many:project_tasks"`
+	OrganizationID uint   `gorm:"uniqueIndex:idx_projects_organization_active_title,where:deleted_at IS NULL"`
+	Title          string `gorm:"uniqueIndex:idx_projects_organization_active_title,where:deleted_at IS NULL"`
+	Description    string
+	StartedAt      time.Time
+	Deadline       time.Time
+	Status         config.StatusChoice `gorm:"default:created"`
+	Executors      []User              `gorm:"many2many:project_users"`
+	Tasks          []Task              `gorm:"many2many:project_tasks"`
+	Teams          []Team              `gorm:"many2many:project_teams"`
 }
 
 func (p *Project) UsersToSchema(users []User) []UserSchema {
@@ -84,13 +108,22 @@ func (p *Project) UsersToSchema(users []User) []UserSchema {
 
 func (p *Project) ToSchema() ProjectSchema {
 	return ProjectSchema{
-		ID:          p.ID,
-		Title:       p.Title,
-		Description: p.Description,
-		StartedAt:   p.StartedAt.Format("01.06.2006"),
-		Deadline:    p.StartedAt.Format("01.06.2006"),
-		Status:      p.Status,
-		Executors:   p.UsersToSchema(p.Executors),
-		Tasks:       p.Tasks,
+		ID:             p.ID,
+		OrganizationID: p.OrganizationID,
+		Title:          p.Title,
+		Description:    p.Description,
+		StartedAt:      p.StartedAt.Format("01.06.2006"),
+		Deadline:       p.StartedAt.Format("01.06.2006"),
+		Status:         p.Status,
+		Executors:      p.UsersToSchema(p.Executors),
+		Tasks:          p.Tasks,
+		Teams:          TeamsToSchema(p.Teams),
 	}
 }
+
+type ProjectUser struct {
+	ProjectID uint                     `gorm:"primaryKey"`
+	UserID    uint                     `gorm:"primaryKey"`
+	Role      config.ProjectRoleChoice `gorm:"default:contributor"`
+	CreatedAt time.Time
+}
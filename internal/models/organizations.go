@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"backend/internal/config"
+
+	"gorm.io/gorm"
+)
+
+type Organization struct {
+	gorm.Model
+	Name string
+	Slug string `gorm:"unique"`
+}
+
+func (o *Organization) ToSchema(role config.OrganizationRoleChoice) OrganizationSchema {
+	return OrganizationSchema{
+		ID:        o.ID,
+		Name:      o.Name,
+		Slug:      o.Slug,
+		Role:      role,
+		CreatedAt: o.CreatedAt,
+	}
+}
+
+type OrganizationMember struct {
+	OrganizationID uint                          `gorm:"primaryKey"`
+	UserID         uint                          `gorm:"primaryKey"`
+	Role           config.OrganizationRoleChoice `gorm:"default:member"`
+	CreatedAt      time.Time
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"time"
+
 	"backend/internal/config"
 )
 
@@ -25,7 +28,12 @@ type UserSchema struct {
 	LastName  string              `json:"last_name"`
 	BirthDate string              `json:"birth_date"`
 	Gender    config.GenderChoice `json:"gender"`
+	Role      config.RoleChoice   `json:"role"`
 	Email     string              `json:"email"`
+
+	EmailVerified bool `json:"email_verified"`
+	TOTPEnabled   bool `json:"totp_enabled"`
+	IsActive      bool `json:"is_active"`
 }
 
 type ProjectCreateSchema struct {
@@ -35,18 +43,20 @@ type ProjectCreateSchema struct {
 	Deadline    string              `json:"deadline"`
 	Status      config.StatusChoice `json:"status"`
 	Executors   []int               `json:"executors"`
-	Tasks       []Task              `json:"tasks"`
+	Teams       []int               `json:"teams"`
 }
 
 type ProjectSchema struct {
-	ID          uint                `json:"id"`
-	Title       string              `json:"title"`
-	Description string              `json:"description"`
-	StartedAt   string              `json:"started_at"`
-	Deadline    string              `json:"deadline"`
-	Status      config.StatusChoice `json:"status"`
-	Executors   []UserSchema        `json:"executors"`
-	Tasks       []Task              `json:"tasks"`
+	ID             uint                `json:"id"`
+	OrganizationID uint                `json:"organization_id"`
+	Title          string              `json:"title"`
+	Description    string              `json:"description"`
+	StartedAt      string              `json:"started_at"`
+	Deadline       string              `json:"deadline"`
+	Status         config.StatusChoice `json:"status"`
+	Executors      []UserSchema        `json:"executors"`
+	Tasks          []Task              `json:"tasks"`
+	Teams          []TeamSchema        `json:"teams"`
 }
 
 type ProjectUpdateSchema struct {
@@ -55,8 +65,6 @@ type ProjectUpdateSchema struct {
 	StartedAt   string              `json:"started_at"`
 	Deadline    string              `json:"deadline"`
 	Status      config.StatusChoice `json:"status"`
-	Executors   []int               `json:"executors"`
-	Tasks       []Task              `json:"tasks"`
 }
 
 type TaskCreateSchema struct {
@@ -66,16 +74,19 @@ type TaskCreateSchema struct {
 	Status      config.StatusChoice `json:"status"`
 	ProjectID   int                 `json:"project_id"`
 	Executors   []int               `json:"executors"`
+	Teams       []int               `json:"teams"`
 }
 
 type TaskSchema struct {
-	ID          uint                `json:"id"`
-	Title       string              `json:"title"`
-	Description string              `json:"description"`
-	Deadline    string              `json:"deadline"`
-	Status      config.StatusChoice `json:"status"`
-	ProjectID   uint                `json:"project_id"`
-	Executors   []UserSchema        `json:"executors"`
+	ID             uint                `json:"id"`
+	OrganizationID uint                `json:"organization_id"`
+	Title          string              `json:"title"`
+	Description    string              `json:"description"`
+	Deadline       string              `json:"deadline"`
+	Status         config.StatusChoice `json:"status"`
+	ProjectID      uint                `json:"project_id"`
+	Executors      []UserSchema        `json:"executors"`
+	Teams          []TeamSchema        `json:"teams"`
 }
 
 type TaskUpdateSchema struct {
@@ -85,4 +96,266 @@ type TaskUpdateSchema struct {
 	Status      config.StatusChoice `json:"status"`
 	ProjectID   int                 `json:"project_id"`
 	Executors   []int               `json:"executors"`
+	Teams       []int               `json:"teams"`
+}
+
+type RefreshInput struct {
+	Refresh string `json:"refresh"`
+}
+
+type RoleInput struct {
+	Role config.RoleChoice `json:"role"`
+}
+
+type ProjectMemberInput struct {
+	Role config.ProjectRoleChoice `json:"role"`
+}
+
+type ProjectMemberSchema struct {
+	User UserSchema               `json:"user"`
+	Role config.ProjectRoleChoice `json:"role"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordInput struct {
+	Token           string `json:"token"`
+	Password        string `json:"password"`
+	PasswordConfirm string `json:"password_confirm"`
+}
+
+type ResendVerificationInput struct {
+	Email string `json:"email"`
+}
+
+type PersonalAccessTokenCreateInput struct {
+	Name      string               `json:"name"`
+	Scopes    []config.ScopeChoice `json:"scopes"`
+	ExpiresAt string               `json:"expires_at"`
+}
+
+type PersonalAccessTokenSchema struct {
+	ID         uint                 `json:"id"`
+	Name       string               `json:"name"`
+	Prefix     string               `json:"prefix"`
+	Scopes     []config.ScopeChoice `json:"scopes"`
+	CreatedAt  time.Time            `json:"created_at"`
+	ExpiresAt  *time.Time           `json:"expires_at"`
+	LastUsedAt *time.Time           `json:"last_used_at"`
+	Token      string               `json:"token,omitempty"`
+}
+
+type MFALoginInput struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TOTPCodeInput struct {
+	Code string `json:"code"`
+}
+
+type ProfileUpdateInput struct {
+	FirstName *string              `json:"first_name"`
+	LastName  *string              `json:"last_name"`
+	BirthDate *string              `json:"birth_date"`
+	Gender    *config.GenderChoice `json:"gender"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password"`
+	Password        string `json:"password"`
+	PasswordConfirm string `json:"password_confirm"`
+}
+
+type ChangeEmailInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type DeleteAccountInput struct {
+	Password string `json:"password"`
+}
+
+type AdminUserUpdateInput struct {
+	FirstName *string              `json:"first_name"`
+	LastName  *string              `json:"last_name"`
+	BirthDate *string              `json:"birth_date"`
+	Gender    *config.GenderChoice `json:"gender"`
+	Email     *string              `json:"email"`
+}
+
+type UserListSchema struct {
+	Results  []UserSchema `json:"results"`
+	Total    int64        `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
+
+type ImpersonationInput struct {
+	Reason string `json:"reason"`
+}
+
+type SessionSchema struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+type OrganizationInput struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type OrganizationSchema struct {
+	ID        uint                          `json:"id"`
+	Name      string                        `json:"name"`
+	Slug      string                        `json:"slug"`
+	Role      config.OrganizationRoleChoice `json:"role,omitempty"`
+	CreatedAt time.Time                     `json:"created_at"`
+}
+
+type OrganizationMemberInput struct {
+	Role config.OrganizationRoleChoice `json:"role"`
+}
+
+type OrganizationMemberSchema struct {
+	User UserSchema                    `json:"user"`
+	Role config.OrganizationRoleChoice `json:"role"`
+}
+
+type OrganizationMembershipSchema struct {
+	UserID uint                          `json:"user_id"`
+	Role   config.OrganizationRoleChoice `json:"role"`
+}
+
+type TeamCreateInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	LeadID      *uint  `json:"lead_id"`
+	Members     []int  `json:"members"`
+}
+
+type TeamUpdateInput struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	LeadID      *uint   `json:"lead_id"`
+}
+
+type TeamSchema struct {
+	ID          uint         `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	LeadID      *uint        `json:"lead_id"`
+	Members     []UserSchema `json:"members"`
+}
+
+type ProjectTeamInput struct {
+	Role config.ProjectRoleChoice `json:"role"`
+}
+
+type ProjectTeamSchema struct {
+	Team TeamSchema               `json:"team"`
+	Role config.ProjectRoleChoice `json:"role"`
+}
+
+type ProjectInvitationInput struct {
+	Email string                   `json:"email"`
+	Role  config.ProjectRoleChoice `json:"role"`
+}
+
+type InvitationTokenInput struct {
+	Token string `json:"token"`
+}
+
+type ProjectInvitationSchema struct {
+	ID           uint                     `json:"id"`
+	ProjectID    uint                     `json:"project_id"`
+	ProjectTitle string                   `json:"project_title,omitempty"`
+	Email        string                   `json:"email"`
+	Role         config.ProjectRoleChoice `json:"role"`
+	Status       string                   `json:"status"`
+	InvitedByID  uint                     `json:"invited_by_id"`
+	SentAt       time.Time                `json:"sent_at"`
+	ExpiresAt    time.Time                `json:"expires_at"`
+}
+
+type AuditLogSchema struct {
+	ID             uint            `json:"id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ActorID        *uint           `json:"actor_id"`
+	ImpersonatorID *uint           `json:"impersonator_id,omitempty"`
+	OrganizationID *uint           `json:"organization_id,omitempty"`
+	Action         string          `json:"action"`
+	EntityType     string          `json:"entity_type"`
+	EntityID       uint            `json:"entity_id"`
+	IP             string          `json:"ip"`
+	RequestID      string          `json:"request_id"`
+	Changes        json.RawMessage `json:"changes"`
+	Details        json.RawMessage `json:"details"`
+}
+
+type AuditLogListSchema struct {
+	Results  []AuditLogSchema `json:"results"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+type ProjectListSchema struct {
+	Results    []ProjectSchema `json:"results"`
+	NextCursor *string         `json:"next_cursor"`
+	Total      int64           `json:"total"`
+}
+
+type TaskListSchema struct {
+	Results    []TaskSchema `json:"results"`
+	NextCursor *string      `json:"next_cursor"`
+	Total      int64        `json:"total"`
+}
+
+type StatusTransitionSchema struct {
+	ID         uint                `json:"id"`
+	EntityType string              `json:"entity_type"`
+	EntityID   uint                `json:"entity_id"`
+	From       config.StatusChoice `json:"from"`
+	To         config.StatusChoice `json:"to"`
+	ActorID    *uint               `json:"actor_id"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+type TransitionRuleSchema struct {
+	From config.StatusChoice `json:"from"`
+	To   config.StatusChoice `json:"to"`
+}
+
+type ProjectTransitionsInput struct {
+	Transitions []TransitionRuleSchema `json:"transitions"`
+}
+
+type ProjectTransitionsSchema struct {
+	Custom      bool                   `json:"custom"`
+	Transitions []TransitionRuleSchema `json:"transitions"`
+}
+
+type TrashedProjectSchema struct {
+	ProjectSchema
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+type TrashedTaskSchema struct {
+	TaskSchema
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+type TrashSchema struct {
+	Projects []TrashedProjectSchema `json:"projects"`
+	Tasks    []TrashedTaskSchema    `json:"tasks"`
 }
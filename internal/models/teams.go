@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"backend/internal/config"
+
+	"gorm.io/gorm"
+)
+
+type Team struct {
+	gorm.Model
+	OrganizationID uint   `gorm:"uniqueIndex:idx_teams_organization_name"`
+	Name           string `gorm:"uniqueIndex:idx_teams_organization_name"`
+	Description    string
+	LeadID         *uint
+	Members        []User `gorm:"many2many:team_members"`
+}
+
+func (t *Team) ToSchema() TeamSchema {
+	members := []UserSchema{}
+	for _, member := range t.Members {
+		members = append(members, member.ToSchema())
+	}
+
+	return TeamSchema{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		LeadID:      t.LeadID,
+		Members:     members,
+	}
+}
+
+func TeamsToSchema(teams []Team) []TeamSchema {
+	serializedTeams := []TeamSchema{}
+
+	for _, team := range teams {
+		serializedTeams = append(serializedTeams, team.ToSchema())
+	}
+
+	return serializedTeams
+}
+
+type ProjectTeam struct {
+	ProjectID uint                     `gorm:"primaryKey"`
+	TeamID    uint                     `gorm:"primaryKey"`
+	Role      config.ProjectRoleChoice `gorm:"default:contributor"`
+	CreatedAt time.Time
+}
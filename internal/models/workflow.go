@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"backend/internal/config"
+)
+
+type StatusTransition struct {
+	ID         uint   `gorm:"primarykey"`
+	EntityType string `gorm:"index:idx_status_transitions_entity"`
+	EntityID   uint   `gorm:"index:idx_status_transitions_entity"`
+	ProjectID  uint   `gorm:"index"`
+	FromStatus config.StatusChoice
+	ToStatus   config.StatusChoice
+	ActorID    *uint `gorm:"index"`
+	CreatedAt  time.Time
+}
+
+func (t *StatusTransition) ToSchema() StatusTransitionSchema {
+	return StatusTransitionSchema{
+		ID:         t.ID,
+		EntityType: t.EntityType,
+		EntityID:   t.EntityID,
+		From:       t.FromStatus,
+		To:         t.ToStatus,
+		ActorID:    t.ActorID,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+type ProjectTransition struct {
+	ProjectID  uint                `gorm:"primaryKey"`
+	FromStatus config.StatusChoice `gorm:"primaryKey"`
+	ToStatus   config.StatusChoice `gorm:"primaryKey"`
+}
@@ -0,0 +1,325 @@
+package oidc
+
+import (
+	"backend/internal/config"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID    = "backend"
+	testRedirectURL = "http://localhost/api/v1/auth/oidc/callback"
+)
+
+type authorization struct {
+	challenge string
+	nonce     string
+}
+
+type mockIdP struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	keyID  string
+	claims jwt.MapClaims
+
+	signingKey   *rsa.PrivateKey
+	signingKeyID string
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp := &mockIdP{
+		t:      t,
+		key:    key,
+		keyID:  "test-key",
+		claims: jwt.MapClaims{},
+		codes:  map[string]authorization{},
+
+		signingKey:   key,
+		signingKeyID: "test-key",
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/token", idp.token)
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func (idp *mockIdP) provider() *Provider {
+	return &Provider{
+		Issuer:      idp.server.URL,
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+		Scopes:      []string{"openid", "email", "profile"},
+		GroupsClaim: "groups",
+		GroupRoles:  map[string]config.RoleChoice{"admins": config.Admin, "staff": config.Member},
+		httpClient:  idp.server.Client(),
+	}
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 idp.server.URL,
+		"authorization_endpoint": idp.server.URL + "/authorize",
+		"token_endpoint":         idp.server.URL + "/token",
+		"jwks_uri":               idp.server.URL + "/jwks",
+	})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": idp.keyID,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	idp.mu.Lock()
+	auth, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	if !ok ||
+		r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("client_id") != testClientID ||
+		r.PostForm.Get("redirect_uri") != testRedirectURL ||
+		CodeChallenge(r.PostForm.Get("code_verifier")) != auth.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"id_token": idp.signIDToken(auth.nonce)})
+}
+
+func (idp *mockIdP) signIDToken(nonce string) string {
+	claims := jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            testClientID,
+		"sub":            "subject-1",
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          nonce,
+		"email":          "Jane@Example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+		"groups":         []string{"staff", "admins"},
+	}
+	for key, value := range idp.claims {
+		claims[key] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = idp.signingKeyID
+
+	signed, err := token.SignedString(idp.signingKey)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+
+	return signed
+}
+
+func (idp *mockIdP) authorize(authURL string) string {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		idp.t.Fatalf("code_challenge_method = %q, want S256", query.Get("code_challenge_method"))
+	}
+
+	code := "code-" + query.Get("state")
+
+	idp.mu.Lock()
+	idp.codes[code] = authorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	idp.mu.Unlock()
+
+	return code
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := idp.provider()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := parsed.Scheme + "://" + parsed.Host + parsed.Path; got != idp.server.URL+"/authorize" {
+		t.Errorf("authorization endpoint = %q", got)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "state",
+		"nonce":                 "nonce",
+		"code_challenge":        CodeChallenge("verifier"),
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if got := parsed.Query().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+
+	if parsed.Query().Get("code_challenge") == "verifier" {
+		t.Error("code verifier leaked into the authorization URL")
+	}
+}
+
+func TestExchange(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := idp.provider()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := provider.Exchange(context.Background(), idp.authorize(authURL), "verifier", "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if identity.Subject != "subject-1" || identity.Email != "Jane@Example.com" || !identity.EmailVerified {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+	if identity.GivenName != "Jane" || identity.FamilyName != "Doe" {
+		t.Errorf("name = %q %q, want Jane Doe", identity.GivenName, identity.FamilyName)
+	}
+	if role, ok := provider.RoleForGroups(identity.Groups); !ok || role != config.Admin {
+		t.Errorf("RoleForGroups(%v) = %q, %v, want admin", identity.Groups, role, ok)
+	}
+}
+
+func TestExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := idp.provider()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Exchange(context.Background(), idp.authorize(authURL), "other-verifier", "nonce"); err == nil {
+		t.Fatal("expected an error for a mismatched code verifier")
+	}
+}
+
+func TestExchangeRejectsNonceMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := idp.provider()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Exchange(context.Background(), idp.authorize(authURL), "verifier", "other-nonce"); err == nil {
+		t.Fatal("expected an error for a mismatched nonce")
+	}
+}
+
+func TestExchangeRejectsInvalidIDToken(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]func(idp *mockIdP){
+		"wrong issuer":   func(idp *mockIdP) { idp.claims["iss"] = "https://evil.example.com" },
+		"wrong audience": func(idp *mockIdP) { idp.claims["aud"] = "someone-else" },
+		"expired":        func(idp *mockIdP) { idp.claims["exp"] = time.Now().Add(-time.Minute).Unix() },
+		"missing email":  func(idp *mockIdP) { idp.claims["email"] = "" },
+		"unknown key":    func(idp *mockIdP) { idp.signingKeyID = "rotated" },
+		"wrong signer":   func(idp *mockIdP) { idp.signingKey = otherKey },
+	}
+
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			provider := idp.provider()
+
+			authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mutate(idp)
+
+			if _, err := provider.Exchange(context.Background(), idp.authorize(authURL), "verifier", "nonce"); err == nil {
+				t.Fatal("expected the id_token to be rejected")
+			}
+		})
+	}
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := idp.provider()
+	provider.Issuer = idp.server.URL + "/tenant"
+
+	if _, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); err == nil {
+		t.Fatal("expected discovery to reject a mismatched issuer")
+	}
+}
+
+func TestRoleForGroups(t *testing.T) {
+	provider := &Provider{GroupRoles: map[string]config.RoleChoice{"admins": config.Admin, "staff": config.Member}}
+
+	if role, ok := provider.RoleForGroups([]string{"staff", "admins"}); !ok || role != config.Admin {
+		t.Errorf("RoleForGroups = %q, %v, want admin", role, ok)
+	}
+	if role, ok := provider.RoleForGroups([]string{"staff"}); !ok || role != config.Member {
+		t.Errorf("RoleForGroups = %q, %v, want member", role, ok)
+	}
+	if _, ok := provider.RoleForGroups([]string{"guests"}); ok {
+		t.Error("RoleForGroups matched an unmapped group")
+	}
+}
@@ -0,0 +1,233 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidLimit  = errors.New("incorrect limit")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+type Kind int
+
+const (
+	Int Kind = iota
+	String
+	Time
+)
+
+type Field struct {
+	Column string
+	Kind   Kind
+}
+
+type Order struct {
+	Field Field
+	Desc  bool
+}
+
+type Page struct {
+	Limit  int
+	Orders []Order
+	After  []interface{}
+	sort   string
+}
+
+type cursor struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+var idField = Field{Column: "id", Kind: Int}
+
+func Parse(c *gin.Context, fields map[string]Field, defaultSort string) (*Page, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DefaultLimit)))
+	if err != nil || limit < 1 || limit > MaxLimit {
+		return nil, ErrInvalidLimit
+	}
+
+	sort := c.DefaultQuery("sort", defaultSort)
+
+	orders, err := parseSort(sort, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Limit: limit, Orders: orders, sort: sort}
+
+	if raw := c.Query("cursor"); raw != "" {
+		if page.After, err = decodeCursor(raw, sort, orders); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+func parseSort(sort string, fields map[string]Field) ([]Order, error) {
+	var orders []Order
+	seen := map[string]bool{}
+
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field %s", name)
+		}
+		if seen[field.Column] {
+			return nil, fmt.Errorf("duplicate sort field %s", name)
+		}
+		seen[field.Column] = true
+
+		orders = append(orders, Order{Field: field, Desc: desc})
+	}
+
+	if !seen[idField.Column] {
+		desc := len(orders) > 0 && orders[len(orders)-1].Desc
+		orders = append(orders, Order{Field: idField, Desc: desc})
+	}
+
+	return orders, nil
+}
+
+func decodeCursor(raw, sort string, orders []Order) ([]interface{}, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var parsed cursor
+	if err := json.Unmarshal(decoded, &parsed); err != nil || parsed.Sort != sort || len(parsed.Values) != len(orders) {
+		return nil, ErrInvalidCursor
+	}
+
+	values := make([]interface{}, len(orders))
+
+	for i, order := range orders {
+		var err error
+
+		switch order.Field.Kind {
+		case Int:
+			var value int64
+			err = json.Unmarshal(parsed.Values[i], &value)
+			values[i] = value
+		case String:
+			var value string
+			err = json.Unmarshal(parsed.Values[i], &value)
+			values[i] = value
+		case Time:
+			var value time.Time
+			err = json.Unmarshal(parsed.Values[i], &value)
+			values[i] = value
+		}
+
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return values, nil
+}
+
+func (p *Page) Apply(query *gorm.DB) *gorm.DB {
+	if p.After != nil {
+		var conditions []string
+		var args []interface{}
+
+		for i, order := range p.Orders {
+			var parts []string
+
+			for j := 0; j < i; j++ {
+				parts = append(parts, p.Orders[j].Field.Column+" = ?")
+				args = append(args, p.After[j])
+			}
+
+			operator := ">"
+			if order.Desc {
+				operator = "<"
+			}
+
+			parts = append(parts, order.Field.Column+" "+operator+" ?")
+			args = append(args, p.After[i])
+
+			conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+		}
+
+		query = query.Where(strings.Join(conditions, " OR "), args...)
+	}
+
+	for _, order := range p.Orders {
+		direction := " ASC"
+		if order.Desc {
+			direction = " DESC"
+		}
+		query = query.Order(order.Field.Column + direction)
+	}
+
+	return query.Limit(p.Limit + 1)
+}
+
+func Trim[T any](db *gorm.DB, p *Page, rows []T) ([]T, *string, error) {
+	if len(rows) <= p.Limit {
+		return rows, nil, nil
+	}
+
+	rows = rows[:p.Limit]
+
+	last := rows[len(rows)-1]
+
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(&last); err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]json.RawMessage, len(p.Orders))
+
+	for i, order := range p.Orders {
+		field := statement.Schema.LookUpField(order.Field.Column)
+		if field == nil {
+			return nil, nil, fmt.Errorf("unknown column %s", order.Field.Column)
+		}
+
+		value, _ := field.ValueOf(context.Background(), reflect.ValueOf(&last).Elem())
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		values[i] = encoded
+	}
+
+	encoded, err := json.Marshal(cursor{Sort: p.sort, Values: values})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := base64.RawURLEncoding.EncodeToString(encoded)
+
+	return rows, &next, nil
+}
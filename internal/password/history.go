@@ -0,0 +1,52 @@
+package password
+
+import (
+	"backend/internal/models"
+	"gorm.io/gorm"
+)
+
+func WasUsedRecently(db *gorm.DB, user *models.User, plainPassword string) (bool, error) {
+	if CurrentPolicy.HistorySize <= 0 {
+		return false, nil
+	}
+
+	hashes := []string{}
+	if user.Password != "" {
+		hashes = append(hashes, user.Password)
+	}
+
+	var previous []string
+	err := db.Model(&models.PasswordHistory{}).
+		Where("user_id = ?", user.ID).
+		Order("created_at DESC").
+		Limit(CurrentPolicy.HistorySize).
+		Pluck("password_hash", &previous).Error
+	if err != nil {
+		return false, err
+	}
+
+	for _, hash := range append(hashes, previous...) {
+		if ok, _, err := Verify(hash, plainPassword); err == nil && ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func Remember(tx *gorm.DB, userID uint, hash string) error {
+	if CurrentPolicy.HistorySize <= 0 || hash == "" {
+		return nil
+	}
+
+	if err := tx.Create(&models.PasswordHistory{UserID: userID, PasswordHash: hash}).Error; err != nil {
+		return err
+	}
+
+	keep := tx.Model(&models.PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(CurrentPolicy.HistorySize)
+
+	return tx.Where("user_id = ? AND id NOT IN (?)", userID, keep).Delete(&models.PasswordHistory{}).Error
+}
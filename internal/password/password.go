@@ -0,0 +1,78 @@
+package password
+
+import (
+	"backend/internal/config"
+	"errors"
+	"log"
+	"os"
+	"sync"
+)
+
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+type Hasher interface {
+	Hash(password string) (string, error)
+	Verify(encoded, password string) (bool, error)
+	Recognizes(encoded string) bool
+	NeedsRehash(encoded string) bool
+}
+
+var (
+	Default Hasher = DefaultArgon2idHasher()
+	legacy  Hasher = &BcryptHasher{Cost: 10}
+)
+
+func InitHasher() {
+	argon2idHasher := &Argon2idHasher{
+		Memory:      uint32(config.GetEnvInt("ARGON2_MEMORY_KIB", 64*1024)),
+		Iterations:  uint32(config.GetEnvInt("ARGON2_ITERATIONS", 3)),
+		Parallelism: uint8(config.GetEnvInt("ARGON2_PARALLELISM", 2)),
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+	bcryptHasher := &BcryptHasher{Cost: config.GetEnvInt("BCRYPT_COST", 10)}
+
+	switch os.Getenv("PASSWORD_HASHER") {
+	case "", "argon2id":
+		Default = argon2idHasher
+		legacy = bcryptHasher
+	case "bcrypt":
+		Default = bcryptHasher
+		legacy = argon2idHasher
+	default:
+		log.Fatalf("Unknown PASSWORD_HASHER %q", os.Getenv("PASSWORD_HASHER"))
+	}
+}
+
+func Hash(password string) (string, error) {
+	return Default.Hash(password)
+}
+
+func Verify(encoded, password string) (ok bool, needsRehash bool, err error) {
+	for _, hasher := range []Hasher{Default, legacy} {
+		if !hasher.Recognizes(encoded) {
+			continue
+		}
+
+		ok, err := hasher.Verify(encoded, password)
+		if err != nil || !ok {
+			return false, false, err
+		}
+
+		return true, hasher != Default || hasher.NeedsRehash(encoded), nil
+	}
+
+	return false, false, ErrUnknownHashFormat
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+func VerifyDummy(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = Default.Hash("dummy-password")
+	})
+	Default.Verify(dummyHash, password)
+}
@@ -0,0 +1,116 @@
+package password
+
+import (
+	"backend/internal/config"
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"log"
+	"os"
+	"strings"
+)
+
+type Policy struct {
+	MinLength          int
+	RequireUpper       bool
+	RequireLower       bool
+	RequireDigit       bool
+	RequireSymbol      bool
+	ForbidPersonalInfo bool
+	HistorySize        int
+}
+
+var CurrentPolicy = Policy{
+	MinLength:          8,
+	RequireDigit:       true,
+	ForbidPersonalInfo: true,
+	HistorySize:        5,
+}
+
+type breachedList struct {
+	prefixes map[string]struct{}
+	lengths  map[int]struct{}
+}
+
+var breached *breachedList
+
+func InitPolicy() {
+	CurrentPolicy = Policy{
+		MinLength:          config.GetEnvInt("PASSWORD_MIN_LENGTH", 8),
+		RequireUpper:       config.GetEnvBool("PASSWORD_REQUIRE_UPPER", false),
+		RequireLower:       config.GetEnvBool("PASSWORD_REQUIRE_LOWER", false),
+		RequireDigit:       config.GetEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol:      config.GetEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		ForbidPersonalInfo: config.GetEnvBool("PASSWORD_FORBID_PERSONAL_INFO", true),
+		HistorySize:        config.GetEnvInt("PASSWORD_HISTORY_SIZE", 5),
+	}
+
+	if path := os.Getenv("PASSWORD_BREACHED_LIST"); path != "" {
+		list, err := LoadBreachedList(path)
+		if err != nil {
+			log.Fatalf("Failed to load breached password list: %v", err)
+		}
+		breached = list
+	}
+}
+
+func LoadBreachedList(path string) (*breachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := &breachedList{prefixes: map[string]struct{}{}, lengths: map[int]struct{}{}}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		prefix := strings.ToUpper(line)
+		list.prefixes[prefix] = struct{}{}
+		list.lengths[len(prefix)] = struct{}{}
+	}
+
+	return list, scanner.Err()
+}
+
+func (l *breachedList) contains(plainPassword string) bool {
+	sum := sha1.Sum([]byte(plainPassword))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	for length := range l.lengths {
+		if length > len(digest) {
+			continue
+		}
+		if _, ok := l.prefixes[digest[:length]]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func IsBreached(plainPassword string) bool {
+	return breached != nil && breached.contains(plainPassword)
+}
+
+func ContainsPersonalInfo(plainPassword string, personal ...string) bool {
+	lowered := strings.ToLower(plainPassword)
+
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if at := strings.IndexByte(value, '@'); at >= 0 {
+			value = value[:at]
+		}
+		if len([]rune(value)) >= 3 && strings.Contains(lowered, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,361 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+var ErrUnsupportedContentType = errors.New("unsupported patch content type")
+
+func Apply(contentType string, document, body []byte) ([]byte, error) {
+	mediaType := ""
+	if contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, ErrUnsupportedContentType
+		}
+		mediaType = parsed
+	}
+
+	switch mediaType {
+	case "", "application/json", MergePatchContentType:
+		return MergePatch(document, body)
+	case JSONPatchContentType:
+		return JSONPatch(document, body)
+	}
+
+	return nil, ErrUnsupportedContentType
+}
+
+func MergePatch(document, body []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	var mergePatch interface{}
+	if err := json.Unmarshal(body, &mergePatch); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+
+	return json.Marshal(merge(target, mergePatch))
+}
+
+func merge(target, mergePatch interface{}) interface{} {
+	patchObject, ok := mergePatch.(map[string]interface{})
+	if !ok {
+		return mergePatch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = merge(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+func JSONPatch(document, body []byte) ([]byte, error) {
+	var root interface{}
+	if err := json.Unmarshal(document, &root); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	var operations []Operation
+	if err := json.Unmarshal(body, &operations); err != nil {
+		return nil, fmt.Errorf("invalid json patch: %w", err)
+	}
+
+	for i, operation := range operations {
+		var err error
+		if root, err = operation.apply(root); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, operation.Op, operation.Path, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+func (o Operation) value() (interface{}, error) {
+	if len(o.Value) == 0 {
+		return nil, errors.New("value is required")
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(o.Value, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (o Operation) apply(root interface{}) (interface{}, error) {
+	switch o.Op {
+	case "add":
+		value, err := o.value()
+		if err != nil {
+			return nil, err
+		}
+		return add(root, o.Path, value)
+	case "remove":
+		root, _, err := remove(root, o.Path)
+		return root, err
+	case "replace":
+		value, err := o.value()
+		if err != nil {
+			return nil, err
+		}
+		if o.Path == "" {
+			return value, nil
+		}
+		root, _, err = remove(root, o.Path)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, o.Path, value)
+	case "move":
+		if o.Path == o.From || strings.HasPrefix(o.Path, o.From+"/") {
+			if o.Path == o.From {
+				return root, nil
+			}
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+		root, value, err := remove(root, o.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, o.Path, value)
+	case "copy":
+		value, err := get(root, o.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, o.Path, deepCopy(value))
+	case "test":
+		expected, err := o.value()
+		if err != nil {
+			return nil, err
+		}
+		actual, err := get(root, o.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return nil, errors.New("test failed")
+		}
+		return root, nil
+	}
+
+	return nil, fmt.Errorf("unknown operation %q", o.Op)
+}
+
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return length, nil
+	}
+
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	limit := length - 1
+	if allowEnd {
+		limit = length
+	}
+	if index > limit {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+
+	return index, nil
+}
+
+func get(root interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	current := root
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q does not exist", pointer)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+	}
+
+	return current, nil
+}
+
+func update(root interface{}, tokens []string, change func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return change(root, tokens[0])
+	}
+
+	token := tokens[0]
+
+	switch node := root.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, errors.New("path does not exist")
+		}
+		updated, err := update(child, tokens[1:], change)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = updated
+		return node, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := update(node[index], tokens[1:], change)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = updated
+		return node, nil
+	}
+
+	return nil, errors.New("path does not exist")
+}
+
+func add(root interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return update(root, tokens, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+		return nil, errors.New("path does not exist")
+	})
+}
+
+func remove(root interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil, errors.New("cannot remove the whole document")
+	}
+
+	var removed interface{}
+
+	root, err = update(root, tokens, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, errors.New("path does not exist")
+			}
+			removed = value
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = node[index]
+			return append(node[:index:index], node[index+1:]...), nil
+		}
+		return nil, errors.New("path does not exist")
+	})
+
+	return root, removed, err
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch node := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(node))
+		for key, child := range node {
+			copied[key] = deepCopy(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(node))
+		for i, child := range node {
+			copied[i] = deepCopy(child)
+		}
+		return copied
+	}
+	return value
+}
@@ -0,0 +1,37 @@
+package patch
+
+import "testing"
+
+func TestJSONPatchValues(t *testing.T) {
+	document := []byte(`{"title":"Launch","teams":[1,2]}`)
+
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{name: "replace with null", body: `[{"op":"replace","path":"/teams","value":null}]`, want: `{"teams":null,"title":"Launch"}`},
+		{name: "add null", body: `[{"op":"add","path":"/description","value":null}]`, want: `{"description":null,"teams":[1,2],"title":"Launch"}`},
+		{name: "test null", body: `[{"op":"replace","path":"/teams","value":null},{"op":"test","path":"/teams","value":null}]`, want: `{"teams":null,"title":"Launch"}`},
+		{name: "missing value", body: `[{"op":"replace","path":"/teams"}]`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patched, err := JSONPatch(document, []byte(test.body))
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", patched)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(patched) != test.want {
+				t.Errorf("patched = %s, want %s", patched, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package permissions
+
+import (
+	"backend/internal/config"
+	"backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func OrganizationRole(db *gorm.DB, organizationID, userID uint) (config.OrganizationRoleChoice, bool) {
+	var membership models.OrganizationMember
+
+	if err := db.Where("organization_id = ? AND user_id = ?", organizationID, userID).First(&membership).Error; err != nil {
+		return "", false
+	}
+
+	return membership.Role, true
+}
+
+func DefaultOrganizationID(db *gorm.DB, userID uint) uint {
+	var membership models.OrganizationMember
+
+	if err := db.Where("user_id = ?", userID).Order("created_at, organization_id").First(&membership).Error; err != nil {
+		return 0
+	}
+
+	return membership.OrganizationID
+}
+
+func OrganizationMemberIDs(db *gorm.DB, organizationID uint) *gorm.DB {
+	return db.Model(&models.OrganizationMember{}).Select("user_id").Where("organization_id = ?", organizationID)
+}
+
+func OrganizationOwnerIDs(db *gorm.DB, organizationID uint) ([]uint, error) {
+	var ids []uint
+
+	err := db.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", organizationID, config.OrganizationOwner).
+		Pluck("user_id", &ids).Error
+
+	return ids, err
+}
+
+func SetOrganizationRole(db *gorm.DB, organizationID, userID uint, role config.OrganizationRoleChoice) error {
+	membership := models.OrganizationMember{OrganizationID: organizationID, UserID: userID, Role: role}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&membership).Error
+}
@@ -0,0 +1,72 @@
+package permissions
+
+import (
+	"backend/internal/config"
+	"backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func ProjectRole(db *gorm.DB, projectID, userID uint) (config.ProjectRoleChoice, bool) {
+	var roles []config.ProjectRoleChoice
+
+	err := db.Raw(
+		"SELECT role FROM project_users WHERE project_id = ? AND user_id = ? "+
+			"UNION ALL SELECT project_teams.role FROM project_teams "+
+			"JOIN team_members ON team_members.team_id = project_teams.team_id "+
+			"WHERE project_teams.project_id = ? AND team_members.user_id = ?",
+		projectID, userID, projectID, userID,
+	).Scan(&roles).Error
+
+	if err != nil || len(roles) == 0 {
+		return "", false
+	}
+
+	best := roles[0]
+	for _, role := range roles[1:] {
+		if role.AtLeast(best) {
+			best = role
+		}
+	}
+
+	return best, true
+}
+
+func DirectProjectRole(db *gorm.DB, projectID, userID uint) (config.ProjectRoleChoice, bool) {
+	var membership models.ProjectUser
+
+	if err := db.Where("project_id = ? AND user_id = ?", projectID, userID).First(&membership).Error; err != nil {
+		return "", false
+	}
+
+	return membership.Role, true
+}
+
+func MemberProjectIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Raw(
+		"SELECT project_id FROM project_users WHERE user_id = ? "+
+			"UNION SELECT project_teams.project_id FROM project_teams "+
+			"JOIN team_members ON team_members.team_id = project_teams.team_id "+
+			"WHERE team_members.user_id = ?",
+		userID, userID,
+	)
+}
+
+func ProjectOwnerIDs(db *gorm.DB, projectID uint) ([]uint, error) {
+	var ids []uint
+
+	err := db.Model(&models.ProjectUser{}).
+		Where("project_id = ? AND role = ?", projectID, config.ProjectOwner).
+		Pluck("user_id", &ids).Error
+
+	return ids, err
+}
+
+func SetProjectRole(db *gorm.DB, projectID, userID uint, role config.ProjectRoleChoice) error {
+	membership := models.ProjectUser{ProjectID: projectID, UserID: userID, Role: role}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&membership).Error
+}
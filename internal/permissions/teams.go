@@ -0,0 +1,45 @@
+package permissions
+
+import (
+	"backend/internal/config"
+	"backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TeamTaskIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Raw(
+		"SELECT task_teams.task_id FROM task_teams "+
+			"JOIN team_members ON team_members.team_id = task_teams.team_id "+
+			"WHERE team_members.user_id = ?",
+		userID,
+	)
+}
+
+func IsTaskTeamMember(db *gorm.DB, taskID, userID uint) bool {
+	var count int64
+
+	db.Table("task_teams").
+		Joins("JOIN team_members ON team_members.team_id = task_teams.team_id").
+		Where("task_teams.task_id = ? AND team_members.user_id = ?", taskID, userID).
+		Count(&count)
+
+	return count > 0
+}
+
+func IsTeamMember(db *gorm.DB, teamID, userID uint) bool {
+	var count int64
+
+	db.Table("team_members").Where("team_id = ? AND user_id = ?", teamID, userID).Count(&count)
+
+	return count > 0
+}
+
+func SetProjectTeamRole(db *gorm.DB, projectID, teamID uint, role config.ProjectRoleChoice) error {
+	assignment := models.ProjectTeam{ProjectID: projectID, TeamID: teamID, Role: role}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "team_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&assignment).Error
+}
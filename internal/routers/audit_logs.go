@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func AuditLogsRouters(router *gin.RouterGroup) {
+	auditLogRouters := router.Group("/audit-logs", auth.Authenticate, auth.DenyPersonalTokens, auth.RequireRoles(config.Admin))
+	{
+		auditLogRouters.GET("", handlers.ReadAuditLogs)
+		auditLogRouters.GET("/export", handlers.ExportAuditLogs)
+	}
+}
@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func InvitationsRouters(router *gin.RouterGroup) {
+	invitationRouters := router.Group("/invitations")
+	{
+		invitationRouters.GET("", auth.Authenticate, auth.DenyPersonalTokens, handlers.ReadMyInvitations)
+		invitationRouters.POST("/accept", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.AcceptInvitation)
+		invitationRouters.POST("/decline", handlers.DeclineInvitation)
+	}
+}
@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func OrganizationsRouters(router *gin.RouterGroup) {
+	organizationRouters := router.Group("/organizations", auth.Authenticate, auth.DenyPersonalTokens)
+	{
+		organizationRouters.GET("", handlers.ReadOrganizations)
+		organizationRouters.POST("", auth.ForbidImpersonation, handlers.CreateOrganization)
+		organizationRouters.GET("/:id", handlers.ReadOrganization)
+		organizationRouters.PATCH("/:id", auth.ForbidImpersonation, handlers.UpdateOrganization)
+		organizationRouters.DELETE("/:id", auth.ForbidImpersonation, handlers.DeleteOrganization)
+		organizationRouters.POST("/:id/switch", auth.ForbidImpersonation, handlers.SwitchOrganization)
+		organizationRouters.GET("/:id/members", handlers.ReadOrganizationMembers)
+		organizationRouters.PUT("/:id/members/:user_id", auth.ForbidImpersonation, handlers.SetOrganizationMember)
+		organizationRouters.DELETE("/:id/members/:user_id", auth.ForbidImpersonation, handlers.RemoveOrganizationMember)
+	}
+}
@@ -2,14 +2,32 @@ package routers
 
 import (
 	"backend/internal/auth"
+	"backend/internal/config"
 	"backend/internal/handlers"
+	"backend/internal/workflow"
 	"github.com/gin-gonic/gin"
 )
 
 func ProjectRouters(router *gin.RouterGroup) {
 	projectRouters := router.Group("/projects")
 	{
-		projectRouters.Any("", auth.Authenticate, handlers.ProjectViewSet)
-		projectRouters.Any("/:id", auth.Authenticate, handlers.ProjectViewSet)
+		projectRouters.Any("", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ProjectViewSet)
+		projectRouters.Any("/:id", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ProjectViewSet)
+		projectRouters.GET("/:id/members", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ProjectMembersViewSet)
+		projectRouters.PUT("/:id/members/:user_id", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ProjectMembersViewSet)
+		projectRouters.DELETE("/:id/members/:user_id", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ProjectMembersViewSet)
+		projectRouters.GET("/:id/invitations", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ReadProjectInvitations)
+		projectRouters.POST("/:id/invitations", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.DenyPersonalTokens, handlers.CreateProjectInvitation)
+		projectRouters.POST("/:id/invitations/:invitation_id/resend", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.DenyPersonalTokens, handlers.ResendProjectInvitation)
+		projectRouters.DELETE("/:id/invitations/:invitation_id", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.RevokeProjectInvitation)
+		projectRouters.GET("/:id/teams", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ProjectTeamsViewSet)
+		projectRouters.PUT("/:id/teams/:team_id", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ProjectTeamsViewSet)
+		projectRouters.DELETE("/:id/teams/:team_id", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ProjectTeamsViewSet)
+		projectRouters.POST("/:id/start", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ProjectStatusAction(workflow.Start))
+		projectRouters.POST("/:id/complete", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ProjectStatusAction(workflow.Complete))
+		projectRouters.POST("/:id/reopen", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ProjectStatusAction(workflow.Reopen))
+		projectRouters.GET("/:id/status-history", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ReadProjectStatusHistory)
+		projectRouters.GET("/:id/transitions", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.ReadProjectTransitions)
+		projectRouters.PUT("/:id/transitions", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope), handlers.SetProjectTransitions)
 	}
 }
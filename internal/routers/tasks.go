@@ -2,14 +2,20 @@ package routers
 
 import (
 	"backend/internal/auth"
+	"backend/internal/config"
 	"backend/internal/handlers"
+	"backend/internal/workflow"
 	"github.com/gin-gonic/gin"
 )
 
 func TasksRouters(router *gin.RouterGroup) {
 	taskRouters := router.Group("/tasks")
 	{
-		taskRouters.Any("", auth.Authenticate, handlers.TaskViewSet)
-		taskRouters.Any("/:id", auth.Authenticate, handlers.TaskViewSet)
+		taskRouters.Any("", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.TasksWriteScope), handlers.TaskViewSet)
+		taskRouters.Any("/:id", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.TasksWriteScope), handlers.TaskViewSet)
+		taskRouters.POST("/:id/start", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.TasksWriteScope), handlers.TaskStatusAction(workflow.Start))
+		taskRouters.POST("/:id/complete", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.TasksWriteScope), handlers.TaskStatusAction(workflow.Complete))
+		taskRouters.POST("/:id/reopen", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.TasksWriteScope), handlers.TaskStatusAction(workflow.Reopen))
+		taskRouters.GET("/:id/status-history", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.TasksWriteScope), handlers.ReadTaskStatusHistory)
 	}
 }
@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func TeamsRouters(router *gin.RouterGroup) {
+	teamRouters := router.Group("/teams", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail, auth.RequireScope(config.ProjectsWriteScope))
+	{
+		teamRouters.GET("", handlers.ReadTeams)
+		teamRouters.POST("", handlers.CreateTeam)
+		teamRouters.GET("/:id", handlers.ReadTeam)
+		teamRouters.PATCH("/:id", handlers.UpdateTeam)
+		teamRouters.DELETE("/:id", handlers.DeleteTeam)
+		teamRouters.PUT("/:id/members/:user_id", handlers.AddTeamMember)
+		teamRouters.DELETE("/:id/members/:user_id", handlers.RemoveTeamMember)
+	}
+}
@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"backend/internal/auth"
+	"backend/internal/config"
+	"backend/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func TrashRouters(router *gin.RouterGroup) {
+	trashRouters := router.Group("/trash", auth.Authenticate, auth.RequireOrganization, auth.RequireVerifiedEmail)
+	{
+		trashRouters.GET("", handlers.ReadTrash)
+		trashRouters.POST("/projects/:id/restore", auth.RequireScope(config.ProjectsWriteScope), handlers.RestoreProject)
+		trashRouters.DELETE("/projects/:id", auth.RequireScope(config.ProjectsWriteScope), handlers.PurgeProject)
+		trashRouters.POST("/tasks/:id/restore", auth.RequireScope(config.TasksWriteScope), handlers.RestoreTask)
+		trashRouters.DELETE("/tasks/:id", auth.RequireScope(config.TasksWriteScope), handlers.PurgeTask)
+	}
+}
@@ -2,6 +2,7 @@ package routers
 
 import (
 	"backend/internal/auth"
+	"backend/internal/config"
 	"backend/internal/handlers"
 	"github.com/gin-gonic/gin"
 )
@@ -11,6 +12,44 @@ func UsersRouters(router *gin.RouterGroup) {
 	{
 		userRouters.POST("/register", handlers.RegisterUser)
 		userRouters.POST("/login", handlers.LoginUser)
+		userRouters.POST("/login/2fa", handlers.VerifyMFALogin)
+		userRouters.GET("/oidc/login", handlers.OIDCLogin)
+		userRouters.GET("/oidc/callback", handlers.OIDCCallback)
+		userRouters.POST("/token/refresh", handlers.RefreshToken)
+		userRouters.GET("/verify", handlers.VerifyEmail)
+		userRouters.POST("/verify/resend", handlers.ResendVerification)
+		userRouters.POST("/password/forgot", handlers.ForgotPassword)
+		userRouters.POST("/password/reset", handlers.ResetPassword)
+		userRouters.POST("/logout", auth.Authenticate, handlers.Logout)
 		userRouters.GET("/profile", auth.Authenticate, handlers.Profile)
+		userRouters.PATCH("/profile", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.UpdateProfile)
+		userRouters.DELETE("/profile", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.DeleteAccount)
+		userRouters.POST("/profile/email", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.ChangeEmail)
+		userRouters.GET("/profile/email/confirm", handlers.ConfirmEmailChange)
+		userRouters.POST("/password/change", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.ChangePassword)
+		userRouters.POST("/2fa/setup", auth.AuthenticateMFAEnrollment, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.SetupTOTP)
+		userRouters.POST("/2fa/confirm", auth.AuthenticateMFAEnrollment, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.ConfirmTOTP)
+		userRouters.POST("/2fa/recovery-codes", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.RegenerateRecoveryCodes)
+		userRouters.DELETE("/2fa", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.DisableTOTP)
+		userRouters.GET("/sessions", auth.Authenticate, auth.DenyPersonalTokens, handlers.ReadSessions)
+		userRouters.DELETE("/sessions", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.RevokeOtherSessions)
+		userRouters.DELETE("/sessions/:id", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.RevokeSession)
+		userRouters.GET("/tokens", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.ReadPersonalTokens)
+		userRouters.POST("/tokens", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.CreatePersonalToken)
+		userRouters.DELETE("/tokens/:id", auth.Authenticate, auth.DenyPersonalTokens, auth.ForbidImpersonation, handlers.RevokePersonalToken)
+	}
+
+	adminRouters := router.Group("/users", auth.Authenticate, auth.DenyPersonalTokens, auth.RequireRoles(config.Admin))
+	{
+		adminRouters.GET("", handlers.ReadUsers)
+		adminRouters.GET("/:id", handlers.ReadUser)
+		adminRouters.PATCH("/:id", handlers.UpdateUser)
+		adminRouters.DELETE("/:id", handlers.DeleteUser)
+		adminRouters.PUT("/:id/role", handlers.AssignRole)
+		adminRouters.POST("/:id/deactivate", handlers.DeactivateUser)
+		adminRouters.POST("/:id/reactivate", handlers.ReactivateUser)
+		adminRouters.POST("/:id/password/reset", handlers.ForcePasswordReset)
+		adminRouters.DELETE("/:id/lockout", handlers.UnlockUser)
+		adminRouters.POST("/:id/impersonate", auth.ForbidImpersonation, handlers.ImpersonateUser)
 	}
 }
@@ -0,0 +1,13 @@
+package routers
+
+import (
+	"backend/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func WellKnownRouters(router *gin.RouterGroup) {
+	wellKnownRouters := router.Group("/.well-known")
+	{
+		wellKnownRouters.GET("/jwks.json", handlers.JWKS)
+	}
+}
@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"backend/internal/config"
+	"backend/internal/events"
+	"backend/internal/models"
+	"backend/internal/workflow"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var expirableStatuses = []config.StatusChoice{config.Created, config.InProcess}
+
+func ExpireOverdue(tx *gorm.DB, now time.Time) (Result, error) {
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	var result Result
+
+	if err := expireProjects(tx, today, &result); err != nil {
+		return Result{}, err
+	}
+
+	if err := expireTasks(tx, today, &result); err != nil {
+		return Result{}, err
+	}
+
+	return result, nil
+}
+
+func expireProjects(tx *gorm.DB, today time.Time, result *Result) error {
+	var projects []models.Project
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("deadline < ? AND status IN ?", today, expirableStatuses).
+		Order("id").
+		Find(&projects).Error
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		err := expire(tx, result, "project", project.ID, project.ID, project.OrganizationID, project.Status, project.Deadline, func() error {
+			return tx.Model(&project).Update("status", config.Expired).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func expireTasks(tx *gorm.DB, today time.Time, result *Result) error {
+	var tasks []models.Task
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("deadline < ? AND status IN ?", today, expirableStatuses).
+		Order("id").
+		Find(&tasks).Error
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		err := expire(tx, result, "task", task.ID, task.ProjectID, task.OrganizationID, task.Status, task.Deadline, func() error {
+			return tx.Model(&task).Update("status", config.Expired).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func expire(tx *gorm.DB, result *Result, entityType string, entityID, projectID, organizationID uint, from config.StatusChoice, deadline time.Time, update func() error) error {
+	if err := workflow.Check(tx, projectID, from, config.Expired); err != nil {
+		if errors.Is(err, workflow.ErrTransitionNotAllowed) {
+			return nil
+		}
+		return err
+	}
+
+	if err := update(); err != nil {
+		return err
+	}
+
+	if err := workflow.Record(tx, entityType, entityID, projectID, from, config.Expired, nil); err != nil {
+		return err
+	}
+
+	result.Audit = append(result.Audit, AuditEntry{
+		Log: models.AuditLog{
+			Action:         entityType + ".expire",
+			EntityType:     entityType,
+			EntityID:       entityID,
+			OrganizationID: &organizationID,
+		},
+		Before: map[string]interface{}{"status": from},
+		After:  map[string]interface{}{"status": config.Expired},
+	})
+
+	result.Events = append(result.Events, events.Event{
+		Type:           entityType + ".expired",
+		EntityType:     entityType,
+		EntityID:       entityID,
+		OrganizationID: organizationID,
+		Data: map[string]interface{}{
+			"project_id":  projectID,
+			"from_status": from,
+			"to_status":   config.Expired,
+			"deadline":    deadline,
+		},
+	})
+
+	return nil
+}
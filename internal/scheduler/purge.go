@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"backend/internal/events"
+	"backend/internal/models"
+	"backend/internal/trash"
+	"gorm.io/gorm"
+	"time"
+)
+
+func PurgeTrash(tx *gorm.DB, now time.Time) (Result, error) {
+	cutoff := now.Add(-trash.Retention())
+
+	var projects []models.Project
+	if err := tx.Unscoped().Where("deleted_at < ?", cutoff).Order("id").Find(&projects).Error; err != nil {
+		return Result{}, err
+	}
+
+	projectIDs := make([]uint, 0, len(projects))
+	for _, project := range projects {
+		projectIDs = append(projectIDs, project.ID)
+	}
+
+	if err := trash.PurgeProjects(tx, projectIDs); err != nil {
+		return Result{}, err
+	}
+
+	var tasks []models.Task
+	if err := tx.Unscoped().Where("deleted_at < ?", cutoff).Order("id").Find(&tasks).Error; err != nil {
+		return Result{}, err
+	}
+
+	taskIDs := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+
+	if err := trash.PurgeTasks(tx, taskIDs); err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+
+	for _, project := range projects {
+		purged(&result, "project", project.ID, project.OrganizationID, project.ToSchema())
+	}
+
+	for _, task := range tasks {
+		purged(&result, "task", task.ID, task.OrganizationID, task.ToSchema())
+	}
+
+	return result, nil
+}
+
+func purged(result *Result, entityType string, entityID, organizationID uint, before interface{}) {
+	result.Audit = append(result.Audit, AuditEntry{
+		Log: models.AuditLog{
+			Action:         entityType + ".purge",
+			EntityType:     entityType,
+			EntityID:       entityID,
+			OrganizationID: &organizationID,
+		},
+		Before:  before,
+		Details: map[string]interface{}{"reason": "retention"},
+	})
+
+	result.Events = append(result.Events, events.Event{
+		Type:           entityType + ".purged",
+		EntityType:     entityType,
+		EntityID:       entityID,
+		OrganizationID: organizationID,
+	})
+}
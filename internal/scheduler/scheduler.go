@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"backend/internal/audit"
+	"backend/internal/config"
+	"backend/internal/events"
+	"backend/internal/models"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+type AuditEntry struct {
+	Log     models.AuditLog
+	Before  interface{}
+	After   interface{}
+	Details map[string]interface{}
+}
+
+type Result struct {
+	Events []events.Event
+	Audit  []AuditEntry
+}
+
+type Job struct {
+	Name    string
+	LockKey int64
+	Run     func(tx *gorm.DB, now time.Time) (Result, error)
+}
+
+var Jobs = []Job{
+	{Name: "expire-overdue", LockKey: 7240001, Run: ExpireOverdue},
+	{Name: "purge-trash", LockKey: 7240002, Run: PurgeTrash},
+}
+
+func Start(db *gorm.DB) {
+	if !config.GetEnvBool("SCHEDULER_ENABLED", true) {
+		log.Println("Scheduler is disabled")
+		return
+	}
+
+	interval := config.GetEnvDuration("SCHEDULER_INTERVAL", time.Minute)
+	if interval <= 0 {
+		log.Fatalf("SCHEDULER_INTERVAL must be positive, got %s", interval)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			RunOnce(db, time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+func RunOnce(db *gorm.DB, now time.Time) {
+	for _, job := range Jobs {
+		if err := runJob(db, job, now); err != nil {
+			log.Printf("Scheduler job %s failed: %v", job.Name, err)
+		}
+	}
+}
+
+func runJob(db *gorm.DB, job Job, now time.Time) error {
+	var result Result
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var acquired bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", job.LockKey).Scan(&acquired).Error; err != nil {
+			return err
+		}
+
+		if !acquired {
+			return nil
+		}
+
+		var err error
+		result, err = job.Run(tx, now)
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range result.Audit {
+		audit.RecordDiff(db, entry.Log, entry.Before, entry.After, entry.Details)
+	}
+
+	for _, event := range result.Events {
+		events.Publish(event)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package trash
+
+import (
+	"backend/internal/config"
+	"backend/internal/models"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrTitleConflict = errors.New("an item with the same title already exists")
+
+func Retention() time.Duration {
+	return config.GetEnvDuration("TRASH_RETENTION", 30*24*time.Hour)
+}
+
+func DeleteProject(tx *gorm.DB, project *models.Project) error {
+	now := tx.NowFunc()
+
+	if err := tx.Model(&models.Task{}).Where("project_id = ?", project.ID).UpdateColumn("deleted_at", now).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(project).UpdateColumn("deleted_at", now).Error; err != nil {
+		return err
+	}
+
+	project.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+
+	return nil
+}
+
+func RestoreProject(tx *gorm.DB, project *models.Project) (int64, error) {
+	var conflicts int64
+
+	if err := tx.Model(&models.Project{}).
+		Where("organization_id = ? AND title = ?", project.OrganizationID, project.Title).
+		Count(&conflicts).Error; err != nil {
+		return 0, err
+	}
+
+	cascadedTasks := tx.Unscoped().Model(&models.Task{}).Select("title").
+		Where("project_id = ? AND deleted_at = ?", project.ID, project.DeletedAt.Time)
+
+	if conflicts == 0 {
+		if err := tx.Model(&models.Task{}).
+			Where("organization_id = ? AND title IN (?)", project.OrganizationID, cascadedTasks).
+			Count(&conflicts).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	if conflicts > 0 {
+		return 0, ErrTitleConflict
+	}
+
+	result := tx.Unscoped().Model(&models.Task{}).
+		Where("project_id = ? AND deleted_at = ?", project.ID, project.DeletedAt.Time).
+		UpdateColumn("deleted_at", nil)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if err := tx.Unscoped().Model(project).UpdateColumn("deleted_at", nil).Error; err != nil {
+		return 0, err
+	}
+
+	project.DeletedAt = gorm.DeletedAt{}
+
+	return result.RowsAffected, nil
+}
+
+func RestoreTask(tx *gorm.DB, task *models.Task) error {
+	var conflicts int64
+
+	if err := tx.Model(&models.Task{}).
+		Where("organization_id = ? AND title = ?", task.OrganizationID, task.Title).
+		Count(&conflicts).Error; err != nil {
+		return err
+	}
+
+	if conflicts > 0 {
+		return ErrTitleConflict
+	}
+
+	if err := tx.Unscoped().Model(task).UpdateColumn("deleted_at", nil).Error; err != nil {
+		return err
+	}
+
+	task.DeletedAt = gorm.DeletedAt{}
+
+	return nil
+}
+
+func PurgeTasks(tx *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	for _, table := range []string{"task_users", "task_teams", "project_tasks"} {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE task_id IN ?", ids).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Where("entity_type = ? AND entity_id IN ?", "task", ids).Delete(&models.StatusTransition{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Task{}).Error
+}
+
+func PurgeProjects(tx *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var taskIDs []uint
+	if err := tx.Unscoped().Model(&models.Task{}).Where("project_id IN ?", ids).Pluck("id", &taskIDs).Error; err != nil {
+		return err
+	}
+
+	if err := PurgeTasks(tx, taskIDs); err != nil {
+		return err
+	}
+
+	for _, table := range []string{"project_users", "project_teams", "project_tasks"} {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE project_id IN ?", ids).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Where("project_id IN ?", ids).Delete(&models.ProjectTransition{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("project_id IN ?", ids).Delete(&models.StatusTransition{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Where("project_id IN ?", ids).Delete(&models.ProjectInvitation{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Project{}).Error
+}
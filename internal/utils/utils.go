@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"time"
@@ -20,7 +24,39 @@ func ParseDateToTime(date string, parsedDate *time.Time) error {
 	return nil
 }
 
+func ParseTimeFilter(value string, endOfDay bool) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+
+	var parsed time.Time
+	if err := ParseDateToTime(value, &parsed); err != nil {
+		return time.Time{}, err
+	}
+
+	if endOfDay {
+		parsed = parsed.Add(24 * time.Hour)
+	}
+
+	return parsed, nil
+}
+
 func RaiseBadRequestError(c *gin.Context, err error) {
 	c.JSON(http.StatusBadRequest, err.Error())
 	c.Abort()
 }
+
+func GenerateRandomToken(size int) (string, error) {
+	buf := make([]byte, size)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
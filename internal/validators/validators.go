@@ -2,12 +2,16 @@ package validators
 
 import (
 	"backend/internal/models"
+	"backend/internal/password"
 	"errors"
 	"fmt"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"log"
 	"regexp"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 type ErrorResponse struct {
@@ -25,41 +29,132 @@ func ValidateRegisterForm(firstName, lastName, email, password, PasswordConfirm
 		validationErrors["last_name"] = "Поле 'last_name' обязательно"
 	}
 
+	if message := ValidateEmail(email); message != "" {
+		validationErrors["email"] = message
+	}
+
+	for field, message := range ValidatePassword(password, PasswordConfirm, firstName, lastName, email) {
+		validationErrors[field] = message
+	}
+
+	return validationErrors
+}
+
+func ValidateEmail(email string) string {
 	if email == "" {
-		validationErrors["email"] = "Поле 'email' обязательно"
-	} else {
-		if !regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`).MatchString(email) {
-			validationErrors["email"] = "Формат поля 'email' некорректный"
-		}
+		return "Поле 'email' обязательно"
 	}
 
-	if len(password) < 6 {
-		validationErrors["password"] = "Длина пароля не может быть меньше шести символов"
+	if !regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`).MatchString(email) {
+		return "Формат поля 'email' некорректный"
 	}
 
-	hasDigit := regexp.MustCompile(`[0-9]`).MatchString
+	return ""
+}
+
+func ValidatePassword(plainPassword, passwordConfirm string, personal ...string) map[string]string {
+	validationErrors := make(map[string]string)
+	policy := password.CurrentPolicy
+
+	var problems []string
 
-	if !hasDigit(password) {
-		validationErrors["password"] = "Пароль должен состоять из букв и цифр"
+	if utf8.RuneCountInString(plainPassword) < policy.MinLength {
+		problems = append(problems, fmt.Sprintf("Длина пароля не может быть меньше %d символов", policy.MinLength))
 	}
 
-	if password != PasswordConfirm {
+	if policy.RequireLower && !strings.ContainsFunc(plainPassword, unicode.IsLower) {
+		problems = append(problems, "Пароль должен содержать строчную букву")
+	}
+
+	if policy.RequireUpper && !strings.ContainsFunc(plainPassword, unicode.IsUpper) {
+		problems = append(problems, "Пароль должен содержать заглавную букву")
+	}
+
+	if policy.RequireDigit && !strings.ContainsFunc(plainPassword, unicode.IsDigit) {
+		problems = append(problems, "Пароль должен содержать цифру")
+	}
+
+	if policy.RequireSymbol && !strings.ContainsFunc(plainPassword, isSymbol) {
+		problems = append(problems, "Пароль должен содержать специальный символ")
+	}
+
+	if policy.ForbidPersonalInfo && password.ContainsPersonalInfo(plainPassword, personal...) {
+		problems = append(problems, "Пароль не должен содержать имя или email")
+	}
+
+	if password.IsBreached(plainPassword) {
+		problems = append(problems, "Пароль найден в утечках данных, выберите другой")
+	}
+
+	if len(problems) > 0 {
+		validationErrors["password"] = strings.Join(problems, "; ")
+	}
+
+	if plainPassword != passwordConfirm {
 		validationErrors["passwordConfirm"] = "Пароли не совпадают"
 	}
 
 	return validationErrors
 }
 
-func ValidateUserLogin(db *gorm.DB, user *models.User, email, password string) error {
+func ValidateNewPassword(db *gorm.DB, user *models.User, plainPassword, passwordConfirm string) map[string]string {
+	validationErrors := ValidatePassword(plainPassword, passwordConfirm, user.FirstName, user.LastName, user.Email)
+
+	if _, ok := validationErrors["password"]; ok {
+		return validationErrors
+	}
+
+	reused, err := password.WasUsedRecently(db, user, plainPassword)
+	if err != nil {
+		log.Printf("Couldn't check password history for user %d: %v", user.ID, err)
+	}
+	if reused {
+		validationErrors["password"] = "Пароль совпадает с одним из недавно использованных"
+	}
+
+	return validationErrors
+}
+
+func isSymbol(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+}
+
+func ValidateUserLogin(db *gorm.DB, user *models.User, email, plainPassword string) error {
 
 	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
+		password.VerifyDummy(plainPassword)
 		return errors.New("неверный логин или пароль")
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	ok, needsRehash, err := password.Verify(user.Password, plainPassword)
+	if err != nil || !ok {
 		return errors.New("неверный логин или пароль")
 	}
 
+	if needsRehash {
+		if hashedPassword, err := password.Hash(plainPassword); err == nil {
+			if err := db.Model(user).Update("password", hashedPassword).Error; err != nil {
+				log.Printf("Couldn't upgrade password hash for user %d: %v", user.ID, err)
+			}
+		}
+	}
+
+	if !user.IsActive {
+		return errors.New("учётная запись деактивирована")
+	}
+
+	if user.PasswordResetRequired {
+		return errors.New("необходимо сбросить пароль")
+	}
+
+	return nil
+}
+
+func ValidateTitle(title string) error {
+	if strings.TrimSpace(title) == "" {
+		return fmt.Errorf("title is required")
+	}
+
 	return nil
 }
 
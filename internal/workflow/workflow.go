@@ -0,0 +1,144 @@
+package workflow
+
+import (
+	"backend/internal/config"
+	"backend/internal/models"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownStatus        = errors.New("unknown status")
+	ErrTransitionNotAllowed = errors.New("status transition is not allowed")
+)
+
+var DefaultTransitions = []models.ProjectTransition{
+	{FromStatus: config.Created, ToStatus: config.InProcess},
+	{FromStatus: config.Created, ToStatus: config.Expired},
+	{FromStatus: config.InProcess, ToStatus: config.Completed},
+	{FromStatus: config.InProcess, ToStatus: config.Expired},
+	{FromStatus: config.Completed, ToStatus: config.InProcess},
+	{FromStatus: config.Expired, ToStatus: config.InProcess},
+}
+
+type Action struct {
+	Name string
+	From []config.StatusChoice
+	To   config.StatusChoice
+}
+
+var (
+	Start    = Action{Name: "start", From: []config.StatusChoice{config.Created}, To: config.InProcess}
+	Complete = Action{Name: "complete", To: config.Completed}
+	Reopen   = Action{Name: "reopen", From: []config.StatusChoice{config.Completed, config.Expired}, To: config.InProcess}
+)
+
+func (a Action) Allows(from config.StatusChoice) bool {
+	if len(a.From) == 0 {
+		return from != a.To
+	}
+	for _, status := range a.From {
+		if status == from {
+			return true
+		}
+	}
+	return false
+}
+
+func Transitions(db *gorm.DB, projectID uint) ([]models.ProjectTransition, bool, error) {
+	var transitions []models.ProjectTransition
+
+	if err := db.Where("project_id = ?", projectID).Order("from_status, to_status").Find(&transitions).Error; err != nil {
+		return nil, false, err
+	}
+
+	if len(transitions) == 0 {
+		return DefaultTransitions, false, nil
+	}
+
+	return transitions, true, nil
+}
+
+func Check(db *gorm.DB, projectID uint, from, to config.StatusChoice) error {
+	if !to.IsValid() {
+		return ErrUnknownStatus
+	}
+
+	if from == to {
+		return nil
+	}
+
+	transitions, _, err := Transitions(db, projectID)
+	if err != nil {
+		return err
+	}
+
+	for _, transition := range transitions {
+		if transition.FromStatus == from && transition.ToStatus == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s -> %s", ErrTransitionNotAllowed, from, to)
+}
+
+func CheckInitial(status config.StatusChoice) error {
+	if !status.IsValid() {
+		return ErrUnknownStatus
+	}
+
+	if status != config.Created {
+		return fmt.Errorf("%w: new items must start as %s", ErrTransitionNotAllowed, config.Created)
+	}
+
+	return nil
+}
+
+func Record(tx *gorm.DB, entityType string, entityID, projectID uint, from, to config.StatusChoice, actorID *uint) error {
+	return tx.Create(&models.StatusTransition{
+		EntityType: entityType,
+		EntityID:   entityID,
+		ProjectID:  projectID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actorID,
+	}).Error
+}
+
+func ValidateTransitions(transitions []models.ProjectTransition) error {
+	seen := map[[2]config.StatusChoice]bool{}
+
+	for _, transition := range transitions {
+		if !transition.FromStatus.IsValid() || !transition.ToStatus.IsValid() {
+			return ErrUnknownStatus
+		}
+		if transition.FromStatus == transition.ToStatus {
+			return fmt.Errorf("transition %s -> %s does not change the status", transition.FromStatus, transition.ToStatus)
+		}
+
+		key := [2]config.StatusChoice{transition.FromStatus, transition.ToStatus}
+		if seen[key] {
+			return fmt.Errorf("duplicate transition %s -> %s", transition.FromStatus, transition.ToStatus)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+func SetTransitions(tx *gorm.DB, projectID uint, transitions []models.ProjectTransition) error {
+	if err := tx.Where("project_id = ?", projectID).Delete(&models.ProjectTransition{}).Error; err != nil {
+		return err
+	}
+
+	if len(transitions) == 0 {
+		return nil
+	}
+
+	for i := range transitions {
+		transitions[i].ProjectID = projectID
+	}
+
+	return tx.Create(&transitions).Error
+}
@@ -1,21 +1,44 @@
 package main
 
 import (
+	"backend/internal/audit"
+	"backend/internal/auth"
 	"backend/internal/database"
+	"backend/internal/events"
+	"backend/internal/mailer"
+	"backend/internal/oidc"
+	"backend/internal/password"
 	"backend/internal/routers"
+	"backend/internal/scheduler"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	database.InitDB()
+	auth.InitSigningKeys()
+	mailer.InitMailer()
+	oidc.InitProvider()
+	password.InitHasher()
+	password.InitPolicy()
+	events.InitPublisher()
+	scheduler.Start(database.DB)
 
 	router := gin.Default()
+	router.Use(audit.RequestID)
+	router.Use(audit.ImpersonationTrail(database.DB))
+
+	routers.WellKnownRouters(&router.RouterGroup)
 
 	APIRouter := router.Group("/api/v1")
 
 	routers.ProjectRouters(APIRouter)
 	routers.UsersRouters(APIRouter)
 	routers.TasksRouters(APIRouter)
+	routers.OrganizationsRouters(APIRouter)
+	routers.TeamsRouters(APIRouter)
+	routers.InvitationsRouters(APIRouter)
+	routers.AuditLogsRouters(APIRouter)
+	routers.TrashRouters(APIRouter)
 
 	router.Run()
 }